@@ -0,0 +1,145 @@
+package biz
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/newrelic/infra-integrations-sdk/v3/data/inventory"
+)
+
+// EnvValuesPolicy defines how the values of the container environment variables are reported in the inventory.
+type EnvValuesPolicy string
+
+const (
+	// EnvValuesHide reports only the environment variable keys, replacing all the values.
+	EnvValuesHide EnvValuesPolicy = "hide"
+	// EnvValuesRedactSensitive replaces the values of the environment variables whose key looks sensitive.
+	EnvValuesRedactSensitive EnvValuesPolicy = "redact-sensitive"
+	// EnvValuesShow reports all the environment variable values as they are.
+	EnvValuesShow EnvValuesPolicy = "show"
+)
+
+var sensitiveEnvKey = regexp.MustCompile(`(?i)(pass|secret|token|key|credential|auth|private|cert)`)
+
+// ParseEnvValuesPolicy validates the given environment variable values policy.
+func ParseEnvValuesPolicy(policy string) (EnvValuesPolicy, error) {
+	switch p := EnvValuesPolicy(policy); p {
+	case EnvValuesHide, EnvValuesRedactSensitive, EnvValuesShow:
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid environment values policy %q, accepted values: %s, %s, %s",
+		policy, EnvValuesHide, EnvValuesRedactSensitive, EnvValuesShow)
+}
+
+// WithEnvValuesPolicy sets how the environment variable values are reported in the container inventory.
+func (mc *MetricsFetcher) WithEnvValuesPolicy(policy EnvValuesPolicy) {
+	mc.envValuesPolicy = policy
+}
+
+// WithRedactor sets the Redactor applied to the process and environment variables in the container inventory.
+func (mc *MetricsFetcher) WithRedactor(redactor *Redactor) {
+	mc.redactor = redactor
+}
+
+// inventory returns the configuration the container is running with as inventory items.
+func (mc *MetricsFetcher) inventory(json *container.InspectResponse) inventory.Items {
+	items := inventory.Items{}
+
+	if hc := json.HostConfig; hc != nil {
+		resources := inventory.Item{}
+		setIfNotZero(resources, "cpuShares", hc.CPUShares)
+		if hc.NanoCPUs != 0 {
+			resources["cpuLimitCores"] = float64(hc.NanoCPUs) / 1e9
+		}
+		setIfNotZero(resources, "cpuQuota", hc.CPUQuota)
+		setIfNotZero(resources, "cpuPeriod", hc.CPUPeriod)
+		setIfNotZero(resources, "cpusetCpus", hc.CpusetCpus)
+		setIfNotZero(resources, "memoryLimitBytes", hc.Memory)
+		setIfNotZero(resources, "memoryReservationBytes", hc.MemoryReservation)
+		setIfNotZero(resources, "memorySwapBytes", hc.MemorySwap)
+		if hc.PidsLimit != nil && *hc.PidsLimit > 0 {
+			resources["pidsLimit"] = *hc.PidsLimit
+		}
+		if len(resources) > 0 {
+			items["resources"] = resources
+		}
+
+		if hc.RestartPolicy.Name != "" {
+			items["restartPolicy"] = inventory.Item{
+				"name":              string(hc.RestartPolicy.Name),
+				"maximumRetryCount": hc.RestartPolicy.MaximumRetryCount,
+			}
+		}
+
+		if hc.NetworkMode != "" {
+			items["network"] = inventory.Item{"mode": string(hc.NetworkMode)}
+		}
+
+		for port, bindings := range hc.PortBindings {
+			hostPorts := make([]string, 0, len(bindings))
+			for _, b := range bindings {
+				hostIP := ""
+				if b.HostIP.IsValid() {
+					hostIP = b.HostIP.String()
+				}
+				hostPorts = append(hostPorts, hostIP+":"+b.HostPort)
+			}
+			sort.Strings(hostPorts)
+			items["portBindings/"+port.String()] = inventory.Item{"hostBindings": strings.Join(hostPorts, ",")}
+		}
+	}
+
+	for _, m := range json.Mounts {
+		item := inventory.Item{
+			"type": string(m.Type),
+			"rw":   m.RW,
+		}
+		setIfNotZero(item, "source", m.Source)
+		setIfNotZero(item, "name", m.Name)
+		setIfNotZero(item, "driver", m.Driver)
+		setIfNotZero(item, "mode", m.Mode)
+		items["mounts/"+strings.TrimPrefix(m.Destination, "/")] = item
+	}
+
+	if c := json.Config; c != nil {
+		process := inventory.Item{}
+		setIfNotZero(process, "entrypoint", mc.redactor.Redact(strings.Join(c.Entrypoint, " ")))
+		setIfNotZero(process, "cmd", mc.redactor.Redact(strings.Join(c.Cmd, " ")))
+		setIfNotZero(process, "user", c.User)
+		setIfNotZero(process, "workingDir", c.WorkingDir)
+		if len(process) > 0 {
+			items["process"] = process
+		}
+
+		for _, env := range c.Env {
+			key, value, _ := strings.Cut(env, "=")
+			items["env/"+key] = inventory.Item{"value": mc.envValue(key, value)}
+		}
+	}
+
+	return items
+}
+
+func (mc *MetricsFetcher) envValue(key, value string) string {
+	switch mc.envValuesPolicy {
+	case EnvValuesShow:
+		return mc.redactor.RedactKeyValue(key, value)
+	case EnvValuesRedactSensitive:
+		if sensitiveEnvKey.MatchString(key) {
+			return redactedValue
+		}
+		return mc.redactor.RedactKeyValue(key, value)
+	default:
+		return redactedValue
+	}
+}
+
+func setIfNotZero[T comparable](item inventory.Item, field string, value T) {
+	var zero T
+	if value != zero {
+		item[field] = value
+	}
+}
@@ -0,0 +1,102 @@
+package biz
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/mount"
+	"github.com/moby/moby/api/types/network"
+	"github.com/newrelic/infra-integrations-sdk/v3/data/inventory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsFetcher_Inventory(t *testing.T) {
+	pidsLimit := int64(100)
+	json := &container.InspectResponse{
+		ID: "abc",
+		HostConfig: &container.HostConfig{
+			NetworkMode:   "bridge",
+			RestartPolicy: container.RestartPolicy{Name: "on-failure", MaximumRetryCount: 3},
+			PortBindings: network.PortMap{
+				network.MustParsePort("80/tcp"): {
+					{HostIP: netip.MustParseAddr("0.0.0.0"), HostPort: "8080"},
+					{HostIP: netip.MustParseAddr("::"), HostPort: "8080"},
+				},
+			},
+			Resources: container.Resources{
+				NanoCPUs:  500000000,
+				Memory:    1024,
+				PidsLimit: &pidsLimit,
+			},
+		},
+		Mounts: []container.MountPoint{
+			{Type: mount.TypeBind, Source: "/etc/config", Destination: "/config", RW: false},
+		},
+		Config: &container.Config{
+			Entrypoint: []string{"/bin/sh", "-c"},
+			Cmd:        []string{"sleep", "10"},
+			User:       "nobody",
+			Env:        []string{"PATH=/bin", "DB_PASSWORD=s3cr3t", "EMPTY="},
+		},
+	}
+
+	mc := NewProcessor(nil, nil, nil, 0)
+	items := mc.inventory(json)
+
+	assert.Equal(t, inventory.Item{"cpuLimitCores": 0.5, "memoryLimitBytes": int64(1024), "pidsLimit": int64(100)}, items["resources"])
+	assert.Equal(t, inventory.Item{"name": "on-failure", "maximumRetryCount": 3}, items["restartPolicy"])
+	assert.Equal(t, inventory.Item{"mode": "bridge"}, items["network"])
+	assert.Equal(t, inventory.Item{"hostBindings": "0.0.0.0:8080,:::8080"}, items["portBindings/80/tcp"])
+	assert.Equal(t, inventory.Item{"type": "bind", "source": "/etc/config", "rw": false}, items["mounts/config"])
+	assert.Equal(t, inventory.Item{"entrypoint": "/bin/sh -c", "cmd": "sleep 10", "user": "nobody"}, items["process"])
+
+	// env values are hidden by default
+	assert.Equal(t, inventory.Item{"value": "(redacted)"}, items["env/PATH"])
+	assert.Equal(t, inventory.Item{"value": "(redacted)"}, items["env/DB_PASSWORD"])
+	assert.Equal(t, inventory.Item{"value": "(redacted)"}, items["env/EMPTY"])
+
+	mc.WithEnvValuesPolicy(EnvValuesRedactSensitive)
+	items = mc.inventory(json)
+	assert.Equal(t, inventory.Item{"value": "/bin"}, items["env/PATH"])
+	assert.Equal(t, inventory.Item{"value": "(redacted)"}, items["env/DB_PASSWORD"])
+
+	mc.WithEnvValuesPolicy(EnvValuesShow)
+	items = mc.inventory(json)
+	assert.Equal(t, inventory.Item{"value": "s3cr3t"}, items["env/DB_PASSWORD"])
+	assert.Equal(t, inventory.Item{"value": ""}, items["env/EMPTY"])
+}
+
+func TestParseEnvValuesPolicy(t *testing.T) {
+	policy, err := ParseEnvValuesPolicy("redact-sensitive")
+	require.NoError(t, err)
+	assert.Equal(t, EnvValuesRedactSensitive, policy)
+
+	_, err = ParseEnvValuesPolicy("everything")
+	assert.Error(t, err)
+}
+
+func TestMetricsFetcher_InventoryRedaction(t *testing.T) {
+	json := &container.InspectResponse{
+		ID: "abc",
+		Config: &container.Config{
+			Cmd: []string{"app", "--token", "abc123"},
+			Env: []string{"DB_PASSWORD=s3cr3t", "DATABASE_URL=postgres://app:s3cr3t@db/app", "PATH=/bin"},
+		},
+	}
+
+	redactor, err := NewRedactor(nil)
+	require.NoError(t, err)
+
+	mc := NewProcessor(nil, nil, nil, 0)
+	mc.WithRedactor(redactor)
+	mc.WithEnvValuesPolicy(EnvValuesShow)
+	items := mc.inventory(json)
+
+	assert.Equal(t, inventory.Item{"cmd": "app --token (redacted)"}, items["process"])
+	assert.Equal(t, inventory.Item{"value": "(redacted)"}, items["env/DB_PASSWORD"])
+	assert.Equal(t, inventory.Item{"value": "postgres://app:(redacted)@db/app"}, items["env/DATABASE_URL"])
+	assert.Equal(t, inventory.Item{"value": "/bin"}, items["env/PATH"])
+	assert.Equal(t, 3, redactor.Count())
+}
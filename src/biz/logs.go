@@ -0,0 +1,111 @@
+package biz
+
+import (
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/moby/moby/api/types/container"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+
+	"github.com/newrelic/nri-docker/src/raw"
+)
+
+const (
+	logOptMaxSize = "max-size"
+	logOptMaxFile = "max-file"
+
+	logsStoreKeyPrefix = "logs-"
+)
+
+// Logs holds the logging configuration of a container and the size of its log files, if any.
+type Logs struct {
+	Driver       string
+	SizeBytes    *uint64
+	MaxSizeBytes uint64
+	MaxFiles     uint64
+	// BytesWrittenPerSecond is the rate at which the active log file grew since the previous sampling. It is nil
+	// on the first sampling and when the file was rotated in between.
+	BytesWrittenPerSecond *float64
+}
+
+// StoredLogsSample holds the size of the active log file of a container, to compute the rate at which logs are
+// written in the next sampling.
+type StoredLogsSample struct {
+	Time            int64
+	ActiveSizeBytes uint64
+}
+
+// WithHostRoot sets the folder where the host root is mounted, used to access container files (such as
+// the log files) when the integration runs inside a container.
+func (mc *MetricsFetcher) WithHostRoot(hostRoot string) {
+	mc.hostRoot = hostRoot
+}
+
+func (mc *MetricsFetcher) logs(json *container.InspectResponse, now time.Time) Logs {
+	logs := Logs{}
+	if json.HostConfig == nil {
+		return logs
+	}
+
+	logConfig := json.HostConfig.LogConfig
+	logs.Driver = logConfig.Type
+
+	if maxSize, ok := logConfig.Config[logOptMaxSize]; ok {
+		value, err := units.RAMInBytes(maxSize)
+		if err != nil {
+			log.Debug("invalid %s log option %q for container %s: %v", logOptMaxSize, maxSize, json.ID, err)
+		} else if value > 0 {
+			logs.MaxSizeBytes = uint64(value)
+		}
+	}
+	if maxFile, ok := logConfig.Config[logOptMaxFile]; ok {
+		value, err := strconv.ParseUint(maxFile, 10, 64)
+		if err != nil {
+			log.Debug("invalid %s log option %q for container %s: %v", logOptMaxFile, maxFile, json.ID, err)
+		} else {
+			logs.MaxFiles = value
+		}
+	}
+
+	logPath := raw.ContainerLogPath(*json)
+	if logPath == "" {
+		return logs
+	}
+
+	// the log path is relative to the host root only if the integration is running in a container.
+	if hostRoot, err := raw.DetectHostRoot(mc.hostRoot, raw.CanAccessDir); err == nil {
+		logPath = filepath.Join(hostRoot, logPath)
+	}
+
+	activeSize, size, err := raw.LogFilesSize(logPath)
+	if err != nil {
+		log.Debug("could not compute log files size for container %s: %v", json.ID, err)
+		return logs
+	}
+	logs.SizeBytes = &size
+	logs.BytesWrittenPerSecond = mc.logBytesWrittenPerSecond(json.ID, activeSize, now)
+
+	return logs
+}
+
+// logBytesWrittenPerSecond returns the growth rate of the active log file since the previous sampling. The size
+// of all the log files can't be used, as it stays constant once the max-file rotated files are kept.
+func (mc *MetricsFetcher) logBytesWrittenPerSecond(containerID string, activeSize uint64, now time.Time) *float64 {
+	key := logsStoreKeyPrefix + containerID
+	previous := StoredLogsSample{}
+	_, err := mc.store.Get(key, &previous)
+	mc.store.Set(key, StoredLogsSample{Time: now.Unix(), ActiveSizeBytes: activeSize})
+	if err != nil {
+		return nil
+	}
+
+	seconds := float64(now.Unix() - previous.Time)
+	// the active file is replaced by an empty one on rotation, so the rate is reset when it shrinks
+	if seconds <= 0 || activeSize < previous.ActiveSizeBytes {
+		return nil
+	}
+	rate := float64(activeSize-previous.ActiveSizeBytes) / seconds
+	return &rate
+}
@@ -0,0 +1,86 @@
+package biz
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsFetcher_Logs(t *testing.T) {
+	hostRoot := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(hostRoot, "proc"), 0o755))
+	containerDir := filepath.Join(hostRoot, "/var/lib/docker/containers/abc")
+	require.NoError(t, os.MkdirAll(containerDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(containerDir, "abc-json.log"), make([]byte, 100), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(containerDir, "abc-json.log.1"), make([]byte, 50), 0o600))
+
+	mc := NewProcessor(persist.NewInMemoryStore(), nil, nil, 0)
+	mc.WithHostRoot(hostRoot)
+	now := time.Now()
+
+	t.Run("json-file with rotation options", func(t *testing.T) {
+		logs := mc.logs(&container.InspectResponse{
+			ID:      "abc",
+			LogPath: "/var/lib/docker/containers/abc/abc-json.log",
+			HostConfig: &container.HostConfig{
+				LogConfig: container.LogConfig{
+					Type:   "json-file",
+					Config: map[string]string{"max-size": "10m", "max-file": "3"},
+				},
+			},
+		}, now)
+		require.NotNil(t, logs.SizeBytes)
+		assert.Equal(t, uint64(150), *logs.SizeBytes)
+		assert.Equal(t, "json-file", logs.Driver)
+		assert.Equal(t, uint64(10*1024*1024), logs.MaxSizeBytes)
+		assert.Equal(t, uint64(3), logs.MaxFiles)
+		assert.Nil(t, logs.BytesWrittenPerSecond, "the rate is computed from the next sampling")
+	})
+
+	t.Run("remote driver", func(t *testing.T) {
+		logs := mc.logs(&container.InspectResponse{
+			ID: "abc",
+			HostConfig: &container.HostConfig{
+				LogConfig: container.LogConfig{Type: "syslog"},
+			},
+		}, now)
+		assert.Equal(t, Logs{Driver: "syslog"}, logs)
+	})
+
+	t.Run("invalid options and missing file", func(t *testing.T) {
+		logs := mc.logs(&container.InspectResponse{
+			ID:      "def",
+			LogPath: "/var/lib/docker/containers/def/def-json.log",
+			HostConfig: &container.HostConfig{
+				LogConfig: container.LogConfig{
+					Type:   "json-file",
+					Config: map[string]string{"max-size": "lots", "max-file": "many"},
+				},
+			},
+		}, now)
+		assert.Equal(t, Logs{Driver: "json-file"}, logs)
+	})
+}
+
+func TestMetricsFetcher_LogBytesWrittenPerSecond(t *testing.T) {
+	mc := NewProcessor(persist.NewInMemoryStore(), nil, nil, 0)
+	now := time.Now()
+
+	assert.Nil(t, mc.logBytesWrittenPerSecond("abc", 1000, now))
+
+	rate := mc.logBytesWrittenPerSecond("abc", 3000, now.Add(10*time.Second))
+	require.NotNil(t, rate)
+	assert.Equal(t, 200.0, *rate)
+
+	assert.Nil(t, mc.logBytesWrittenPerSecond("abc", 100, now.Add(20*time.Second)), "the file was rotated")
+
+	rate = mc.logBytesWrittenPerSecond("abc", 600, now.Add(30*time.Second))
+	require.NotNil(t, rate, "the rate is computed again after the rotation")
+	assert.Equal(t, 50.0, *rate)
+}
@@ -9,12 +9,20 @@ import (
 	"strings"
 	"time"
 
+	"github.com/moby/moby/api/types/container"
+	"github.com/newrelic/infra-integrations-sdk/v3/data/inventory"
 	"github.com/newrelic/infra-integrations-sdk/v3/log"
 	"github.com/newrelic/infra-integrations-sdk/v3/persist"
 
 	"github.com/newrelic/nri-docker/src/raw"
 )
 
+// exit codes of the processes killed by the SIGKILL and SIGTERM signals
+const (
+	exitCodeSIGKILL = 128 + 9
+	exitCodeSIGTERM = 128 + 15
+)
+
 var ErrExitedContainerExpired = errors.New("container exited TTL expired")
 var ErrExitedContainerUnexpired = errors.New("exited containers have no metrics to fetch")
 
@@ -30,13 +38,30 @@ type Sample struct {
 	BlkIO        BlkIO
 	CPU          CPU
 	Memory       Memory
+	Logs         Logs
 	RestartCount int
+	// StartLatencySeconds is the time the container took from its creation to its start. It is nil if unknown or
+	// if the container was restarted or stopped and started again, as the start time is then the latest one.
+	StartLatencySeconds *float64
+	// Inventory and Security are populated for both running and exited containers
+	Inventory inventory.Items
+	Security  *Security
+	// Exit is only populated for exited containers
+	Exit *Exit
+}
+
+// Exit describes how an exited container finished
+type Exit struct {
+	Code       int
+	FinishedAt string
+	Reason     string
 }
 
 // Pids section of a container sample
 type Pids raw.Pids
 
-// Network section of a container sample
+// Network section of a container sample. Its bytes rates are computed from the counters if the source doesn't
+// report them.
 type Network raw.Network
 
 // BlkIO stands for Block I/O stats
@@ -85,7 +110,7 @@ type Memory struct {
 
 // Processer defines the most essential interface of an exportable container Processer
 type Processer interface {
-	Process(containerID string) (Sample, error)
+	Process(ctx context.Context, containerID string) (Sample, error)
 }
 
 // MetricsFetcher fetches the container system-level metrics from different sources and processes it to export
@@ -97,6 +122,9 @@ type MetricsFetcher struct {
 	exitedContainerTTL time.Duration
 	getRuntimeNumCPU   func() int
 	platform           string
+	hostRoot           string
+	envValuesPolicy    EnvValuesPolicy
+	redactor           *Redactor
 }
 
 // NewProcessor creates a MetricsFetcher from implementations of its required components
@@ -108,6 +136,7 @@ func NewProcessor(store persist.Storer, fetcher raw.Fetcher, inspector raw.Docke
 		exitedContainerTTL: exitedContainerTTL,
 		getRuntimeNumCPU:   runtime.NumCPU,
 		platform:           runtime.GOOS,
+		envValuesPolicy:    EnvValuesHide,
 	}
 }
 
@@ -117,10 +146,10 @@ func (mc *MetricsFetcher) WithRuntimeNumCPUfunc(rcFunc func() int) {
 }
 
 // Process returns a metrics Sample of the container with the given ID
-func (mc *MetricsFetcher) Process(containerID string) (Sample, error) {
+func (mc *MetricsFetcher) Process(ctx context.Context, containerID string) (Sample, error) {
 	metrics := Sample{}
 
-	json, err := mc.inspector.ContainerInspect(context.Background(), containerID)
+	json, err := mc.inspector.ContainerInspect(ctx, containerID)
 	if err != nil {
 		return metrics, err
 	}
@@ -128,6 +157,9 @@ func (mc *MetricsFetcher) Process(containerID string) (Sample, error) {
 		return metrics, errors.New("empty container inspect result")
 	}
 
+	metrics.Inventory = mc.inventory(&json)
+	metrics.Security = mc.security(&json)
+
 	// TODO: move logic to skip container without State to Docker specific code.
 	if json.State == nil {
 		log.Debug("invalid container %s JSON: missing State", containerID)
@@ -142,26 +174,74 @@ func (mc *MetricsFetcher) Process(containerID string) (Sample, error) {
 			return metrics, ErrExitedContainerExpired
 		}
 
+		metrics.Exit = &Exit{
+			Code:       json.State.ExitCode,
+			FinishedAt: json.State.FinishedAt,
+			Reason:     stopReason(json.State),
+		}
+
 		// There are no metrics to fetch from an exited container.
 		return metrics, ErrExitedContainerUnexpired
 	}
 
 	// Fetch metrics from non exited containers
-	rawMetrics, err := mc.fetcher.Fetch(json)
+	rawMetrics, err := mc.fetcher.Fetch(ctx, json)
 	if err != nil {
 		return metrics, err
 	}
 
-	metrics.Network = Network(rawMetrics.Network)
+	metrics.Network = mc.network(rawMetrics)
 	metrics.BlkIO = mc.blkIO(rawMetrics.Blkio)
 	metrics.CPU = mc.cpu(rawMetrics, &json)
 	metrics.Pids = Pids(rawMetrics.Pids)
 	metrics.Memory = mc.memory(rawMetrics.Memory, &json)
+	metrics.Logs = mc.logs(&json, rawMetrics.Time)
 	metrics.RestartCount = json.RestartCount
+	metrics.StartLatencySeconds = startLatency(&json)
 
 	return metrics, nil
 }
 
+// startLatency returns the seconds between the creation and the start of a container which was never restarted.
+// RestartCount only counts the restarts of the restart policy, so the containers restarted manually are told by
+// having finished before their latest start.
+func startLatency(json *container.InspectResponse) *float64 {
+	if json.State == nil || json.RestartCount > 0 {
+		return nil
+	}
+	created, err := time.Parse(time.RFC3339Nano, json.Created)
+	if err != nil || created.IsZero() {
+		return nil
+	}
+	started, err := time.Parse(time.RFC3339Nano, json.State.StartedAt)
+	if err != nil || started.IsZero() || started.Before(created) {
+		return nil
+	}
+	if finished, err := time.Parse(time.RFC3339Nano, json.State.FinishedAt); err == nil && !finished.IsZero() && finished.Before(started) {
+		return nil
+	}
+	latency := started.Sub(created).Seconds()
+	return &latency
+}
+
+// stopReason returns why the container stopped, as far as it can be told from its state.
+func stopReason(state *container.State) string {
+	switch {
+	case state.OOMKilled:
+		return "OOMKilled"
+	case state.Error != "":
+		return "Error: " + state.Error
+	case state.ExitCode == 0:
+		return "Completed"
+	case state.ExitCode == exitCodeSIGKILL:
+		return "Killed"
+	case state.ExitCode == exitCodeSIGTERM:
+		return "Terminated"
+	default:
+		return "Error"
+	}
+}
+
 func (mc *MetricsFetcher) isExpired(finishedAt string) (bool, error) {
 	if mc.exitedContainerTTL == 0 {
 		return false, nil
@@ -113,9 +113,12 @@ func (mc *MetricsFetcher) cpu(metrics raw.Metrics, json *container.InspectRespon
 
 	cpu := CPU{}
 
-	// Set LimitCores to first honor CPU quota if any; otherwise set it to runtime.CPU().
+	// Set LimitCores to first honor CPU quota if any, then the limit inherited from the parent cgroup
+	// (e.g. Kubernetes pods); otherwise set it to runtime.CPU().
 	if json.HostConfig != nil && json.HostConfig.NanoCPUs != 0 {
 		cpu.LimitCores = float64(json.HostConfig.NanoCPUs) / 1e9
+	} else if metrics.CPU.LimitCores > 0 {
+		cpu.LimitCores = metrics.CPU.LimitCores
 	} else {
 		// TODO: if newrelic-infra is in a limited cpus container, this may report the number of cpus of the
 		// 	newrelic-infra container if the container has no CPU quota
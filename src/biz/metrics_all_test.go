@@ -66,6 +66,23 @@ func TestMetricsFetcher_CPU_LimitCores(t *testing.T) {
 			},
 			want: 2,
 		},
+		{
+			name: "LimitCores honors the limit inherited from the parent cgroup when no CPU quota set",
+			args: args{
+				cpu: raw.Metrics{
+					CPU: raw.CPU{
+						LimitCores: 1.5,
+					},
+				},
+				json: &container.InspectResponse{
+					HostConfig: &container.HostConfig{},
+				},
+			},
+			runtimeCPUMockFunc: func() int {
+				return 2
+			},
+			want: 1.5,
+		},
 	}
 
 	for _, tt := range tests {
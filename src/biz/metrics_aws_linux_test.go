@@ -1,6 +1,7 @@
 package biz
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -31,7 +32,7 @@ func TestFargateMetrics(t *testing.T) {
 	require.NoError(t, err)
 
 	metrics := NewProcessor(persist.NewInMemoryStore(), fetcher, inspector, 0)
-	samples, err := metrics.Process(fargateContainerID)
+	samples, err := metrics.Process(context.Background(), fargateContainerID)
 	require.NoError(t, err)
 
 	assert.Equal(t, uint64(200704), samples.Memory.CacheUsageBytes)
@@ -40,6 +41,8 @@ func TestFargateMetrics(t *testing.T) {
 	assert.Equal(t, uint64(11292672), samples.Memory.UsageBytes)
 	assert.Equal(t, 4.20684814453125, samples.Memory.UsagePercent)
 
+	assert.Equal(t, 0.5, samples.CPU.LimitCores, "task CPU is used as limit")
+
 	assert.Equal(t, uint64(11), samples.Pids.Current)
 	assert.Equal(t, uint64(0), samples.Pids.Limit)
 
@@ -49,6 +52,8 @@ func TestFargateMetrics(t *testing.T) {
 	assert.Equal(t, float64(957), *samples.BlkIO.TotalWriteCount)
 
 	assert.Empty(t, samples.Network)
+
+	assert.Nil(t, samples.Security, "the user of Fargate containers is unknown, so they are not reported as root")
 }
 
 func TestFargateMetricsV4(t *testing.T) {
@@ -64,7 +69,7 @@ func TestFargateMetricsV4(t *testing.T) {
 	require.NoError(t, err)
 
 	metrics := NewProcessor(persist.NewInMemoryStore(), fetcher, inspector, 0)
-	samples, err := metrics.Process(fargateContainerID)
+	samples, err := metrics.Process(context.Background(), fargateContainerID)
 	require.NoError(t, err)
 
 	assert.Equal(t, uint64(0), samples.Memory.CacheUsageBytes)
@@ -89,6 +94,50 @@ func TestFargateMetricsV4(t *testing.T) {
 	assert.Equal(t, int64(0), samples.Network.TxDropped)
 	assert.Equal(t, int64(3), samples.Network.TxErrors)
 	assert.Equal(t, int64(2), samples.Network.TxPackets)
+	require.NotNil(t, samples.Network.RxBytesPerSecond, "v4 endpoint reports network rates")
+	assert.Equal(t, float64(0), *samples.Network.RxBytesPerSecond)
+
+	task, err := inspector.TaskMetadata(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, task.EphemeralStorageMetrics, "v4 endpoint reports the ephemeral storage")
+	assert.Equal(t, aws.EphemeralStorageMetrics{Utilized: 221, Reserved: 20496}, *task.EphemeralStorageMetrics)
+	assert.Equal(t, "EC2", task.LaunchType)
+	assert.Equal(t, "arn:aws:ecs:us-west-2:111122223333:container/abb51bdd-11b4-467f-8f6c-adcfe1fe059d", task.Containers[1].ContainerARN)
+	assert.Equal(t, "/ecs/metadata", task.Containers[1].LogOptions["awslogs-group"])
+}
+
+func TestFargateMetricsCgroupV2(t *testing.T) {
+	ts, cleanup := startMetadataEndpointStub(t, fargateTaskID)
+	defer cleanup()
+	baseURL, err := url.Parse(fmt.Sprintf("%s/v4-cgroupv2/%s", ts.URL, fargateTaskID))
+	require.NoError(t, err)
+
+	fetcher, err := aws.NewFargateFetcher(baseURL)
+	require.NoError(t, err)
+
+	inspector, err := aws.NewFargateInspector(baseURL)
+	require.NoError(t, err)
+
+	metrics := NewProcessor(persist.NewInMemoryStore(), fetcher, inspector, 0)
+	samples, err := metrics.Process(context.Background(), fargateContainerID)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(5496832), samples.Memory.CacheUsageBytes)
+	assert.Equal(t, uint64(0x8000000), samples.Memory.MemLimitBytes)
+	assert.Equal(t, uint64(3080192), samples.Memory.RSSUsageBytes)
+	assert.Equal(t, uint64(3080192), samples.Memory.UsageBytes)
+	assert.Equal(t, uint64(65536+1031024), samples.Memory.KernelUsageBytes)
+	assert.Equal(t, 2.294921875, samples.Memory.UsagePercent)
+
+	assert.Equal(t, uint64(4), samples.Pids.Current)
+	assert.Equal(t, uint64(4611), samples.Pids.Limit)
+
+	assert.Equal(t, int64(1436), samples.Network.RxBytes)
+	assert.Equal(t, int64(1250), samples.Network.TxBytes)
+	require.NotNil(t, samples.Network.RxBytesPerSecond)
+	assert.Equal(t, 118.3, *samples.Network.RxBytesPerSecond)
+	require.NotNil(t, samples.Network.TxBytesPerSecond)
+	assert.Equal(t, 97.6, *samples.Network.TxBytesPerSecond)
 }
 
 func startMetadataEndpointStub(t *testing.T, taskID string) (server *httptest.Server, cleanup func()) {
@@ -104,10 +153,14 @@ func startMetadataEndpointStub(t *testing.T, taskID string) (server *httptest.Se
 				response, err = ioutil.ReadFile("testdata/task_metadata_response.json")
 			case fmt.Sprintf("/v3/%s/task/stats", taskID):
 				response, err = ioutil.ReadFile("testdata/task_container_stats_response.json")
-			case fmt.Sprintf("/v4/%s/task", taskID):
+			case fmt.Sprintf("/v4/%s/task", taskID), fmt.Sprintf("/v4/%s/taskWithTags", taskID):
 				response, err = ioutil.ReadFile("testdata/v4_task_metadata_response.json")
 			case fmt.Sprintf("/v4/%s/task/stats", taskID):
 				response, err = ioutil.ReadFile("testdata/v4_task_container_stats_response.json")
+			case fmt.Sprintf("/v4-cgroupv2/%s/task", taskID):
+				response, err = ioutil.ReadFile("testdata/v4_task_metadata_response.json")
+			case fmt.Sprintf("/v4-cgroupv2/%s/task/stats", taskID):
+				response, err = ioutil.ReadFile("testdata/v4_cgroupv2_task_container_stats_response.json")
 			}
 			require.NoError(t, err)
 
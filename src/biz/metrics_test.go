@@ -0,0 +1,57 @@
+package biz
+
+import (
+	"testing"
+	"time"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStopReason(t *testing.T) {
+	tests := map[string]struct {
+		state container.State
+		want  string
+	}{
+		"completed":    {state: container.State{ExitCode: 0}, want: "Completed"},
+		"oom killed":   {state: container.State{ExitCode: 137, OOMKilled: true}, want: "OOMKilled"},
+		"killed":       {state: container.State{ExitCode: 137}, want: "Killed"},
+		"terminated":   {state: container.State{ExitCode: 143}, want: "Terminated"},
+		"error":        {state: container.State{ExitCode: 1}, want: "Error"},
+		"daemon error": {state: container.State{ExitCode: 127, Error: "executable file not found"}, want: "Error: executable file not found"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, stopReason(&tt.state))
+		})
+	}
+}
+
+func TestStartLatency(t *testing.T) {
+	created := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	inspect := func(started time.Time, restarts int) *container.InspectResponse {
+		return &container.InspectResponse{
+			Created:      created.Format(time.RFC3339Nano),
+			State:        &container.State{StartedAt: started.Format(time.RFC3339Nano)},
+			RestartCount: restarts,
+		}
+	}
+
+	latency := startLatency(inspect(created.Add(1500*time.Millisecond), 0))
+	if assert.NotNil(t, latency) {
+		assert.Equal(t, 1.5, *latency)
+	}
+	assert.Nil(t, startLatency(inspect(created.Add(time.Hour), 1)), "restarted containers")
+
+	manuallyRestarted := inspect(created.Add(time.Hour), 0)
+	manuallyRestarted.State.FinishedAt = created.Add(time.Minute).Format(time.RFC3339Nano)
+	assert.Nil(t, startLatency(manuallyRestarted), "manually restarted containers")
+
+	exited := inspect(created.Add(time.Second), 0)
+	exited.State.FinishedAt = created.Add(time.Hour).Format(time.RFC3339Nano)
+	if latency := startLatency(exited); assert.NotNil(t, latency, "exited containers") {
+		assert.Equal(t, 1.0, *latency)
+	}
+	assert.Nil(t, startLatency(inspect(time.Time{}, 0)), "containers not started")
+	assert.Nil(t, startLatency(&container.InspectResponse{Created: created.Format(time.RFC3339Nano)}), "containers without state")
+}
@@ -0,0 +1,61 @@
+package biz
+
+import (
+	"time"
+
+	"github.com/newrelic/nri-docker/src/raw"
+)
+
+const networkStoreKeyPrefix = "network-"
+
+// StoredNetworkSample holds the network bytes counters of a container, to compute the bytes rates in the next
+// sampling.
+type StoredNetworkSample struct {
+	Time    int64
+	RxBytes int64
+	TxBytes int64
+}
+
+// network returns the network metrics of the container. The bytes rates reported by the source are used as they
+// are, so they don't depend on the previous sampling; otherwise they are computed from the stored counters.
+func (mc *MetricsFetcher) network(metrics raw.Metrics) Network {
+	network := Network(metrics.Network)
+	if network.RxBytesPerSecond != nil && network.TxBytesPerSecond != nil {
+		return network
+	}
+
+	rxRate, txRate := mc.networkBytesPerSecond(metrics.ContainerID, metrics.Network, metrics.Time)
+	if network.RxBytesPerSecond == nil {
+		network.RxBytesPerSecond = rxRate
+	}
+	if network.TxBytesPerSecond == nil {
+		network.TxBytesPerSecond = txRate
+	}
+	return network
+}
+
+// networkBytesPerSecond returns the received and transmitted bytes rates since the previous sampling. They are nil
+// on the first sampling and when the counters were reset in between, e.g. the container was restarted.
+func (mc *MetricsFetcher) networkBytesPerSecond(containerID string, net raw.Network, now time.Time) (*float64, *float64) {
+	key := networkStoreKeyPrefix + containerID
+	previous := StoredNetworkSample{}
+	_, err := mc.store.Get(key, &previous)
+	mc.store.Set(key, StoredNetworkSample{Time: now.Unix(), RxBytes: net.RxBytes, TxBytes: net.TxBytes})
+	if err != nil {
+		return nil, nil
+	}
+
+	seconds := float64(now.Unix() - previous.Time)
+	if seconds <= 0 {
+		return nil, nil
+	}
+	return counterRate(previous.RxBytes, net.RxBytes, seconds), counterRate(previous.TxBytes, net.TxBytes, seconds)
+}
+
+func counterRate(previous, current int64, seconds float64) *float64 {
+	if current < previous {
+		return nil
+	}
+	rate := float64(current-previous) / seconds
+	return &rate
+}
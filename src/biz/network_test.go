@@ -0,0 +1,45 @@
+package biz
+
+import (
+	"testing"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/nri-docker/src/raw"
+)
+
+func TestMetricsFetcher_Network(t *testing.T) {
+	mc := NewProcessor(persist.NewInMemoryStore(), nil, nil, 0)
+	now := time.Now()
+	sample := func(rx, tx int64, at time.Time) Network {
+		return mc.network(raw.Metrics{ContainerID: "abc", Time: at, Network: raw.Network{RxBytes: rx, TxBytes: tx}})
+	}
+
+	first := sample(1000, 500, now)
+	assert.Nil(t, first.RxBytesPerSecond)
+	assert.Nil(t, first.TxBytesPerSecond)
+
+	second := sample(3000, 1500, now.Add(10*time.Second))
+	require.NotNil(t, second.RxBytesPerSecond)
+	require.NotNil(t, second.TxBytesPerSecond)
+	assert.Equal(t, 200.0, *second.RxBytesPerSecond)
+	assert.Equal(t, 100.0, *second.TxBytesPerSecond)
+
+	restarted := sample(100, 50, now.Add(20*time.Second))
+	assert.Nil(t, restarted.RxBytesPerSecond, "the counters were reset")
+	assert.Nil(t, restarted.TxBytesPerSecond)
+
+	t.Run("reported rates are used as they are", func(t *testing.T) {
+		rxRate, txRate := 118.3, 97.6
+		network := mc.network(raw.Metrics{ContainerID: "fargate", Time: now, Network: raw.Network{
+			RxBytes:          1000,
+			RxBytesPerSecond: &rxRate,
+			TxBytesPerSecond: &txRate,
+		}})
+		assert.Equal(t, &rxRate, network.RxBytesPerSecond)
+		assert.Equal(t, &txRate, network.TxBytesPerSecond)
+	})
+}
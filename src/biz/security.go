@@ -0,0 +1,194 @@
+package biz
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/moby/moby/api/types/container"
+
+	"github.com/newrelic/nri-docker/src/raw"
+)
+
+const (
+	seccompDefault    = "default"
+	seccompUnconfined = "unconfined"
+	seccompCustom     = "custom"
+
+	maxRiskScore = 100
+)
+
+// sensitiveHostPaths are host paths that give a container control over the host or the Docker daemon when mounted.
+var sensitiveHostPaths = map[string]struct{}{
+	"/":                    {},
+	"/proc":                {},
+	"/sys":                 {},
+	"/etc":                 {},
+	"/var/run/docker.sock": {},
+	"/run/docker.sock":     {},
+}
+
+// dangerousCapabilities are the capabilities that allow escaping the container isolation.
+var dangerousCapabilities = map[string]struct{}{
+	"ALL":             {},
+	"SYS_ADMIN":       {},
+	"SYS_MODULE":      {},
+	"SYS_PTRACE":      {},
+	"SYS_RAWIO":       {},
+	"NET_ADMIN":       {},
+	"DAC_READ_SEARCH": {},
+}
+
+// Security holds the security posture of a container, as configured on its creation.
+type Security struct {
+	Privileged      bool
+	CapAdd          []string
+	CapDrop         []string
+	SeccompProfile  string
+	AppArmorProfile string
+	SELinuxLabel    string
+	ReadonlyRootfs  bool
+	User            string
+	RunsAsRoot      bool
+	HostPID         bool
+	HostIPC         bool
+	HostNetwork     bool
+	UsernsMode      string
+	SensitiveMounts []string
+	RiskScore       int
+}
+
+// security returns the security posture of the container, or nil if its HostConfig is not available or if it is
+// a Fargate container, whose user and security options are not reported by the task metadata endpoint.
+func (mc *MetricsFetcher) security(json *container.InspectResponse) *Security {
+	hc := json.HostConfig
+	if hc == nil {
+		return nil
+	}
+	if json.Config != nil && raw.IsFargateContainer(json.Config.Labels) {
+		return nil
+	}
+
+	s := &Security{
+		Privileged:      hc.Privileged,
+		CapAdd:          normalizeCapabilities(hc.CapAdd),
+		CapDrop:         normalizeCapabilities(hc.CapDrop),
+		SeccompProfile:  seccompDefault,
+		AppArmorProfile: json.AppArmorProfile,
+		SELinuxLabel:    json.ProcessLabel,
+		ReadonlyRootfs:  hc.ReadonlyRootfs,
+		HostPID:         hc.PidMode.IsHost(),
+		HostIPC:         hc.IpcMode.IsHost(),
+		HostNetwork:     hc.NetworkMode.IsHost(),
+		UsernsMode:      string(hc.UsernsMode),
+	}
+
+	// SecurityOpt entries have the format "<option>=<value>" or "<option>:<value>" (deprecated)
+	for _, opt := range hc.SecurityOpt {
+		key, value, found := strings.Cut(opt, "=")
+		if !found {
+			key, value, _ = strings.Cut(opt, ":")
+		}
+		switch key {
+		case "seccomp":
+			if value == seccompUnconfined {
+				s.SeccompProfile = seccompUnconfined
+			} else {
+				// the value contains the whole JSON profile
+				s.SeccompProfile = seccompCustom
+			}
+		case "apparmor":
+			s.AppArmorProfile = value
+		case "label":
+			if value == "disable" {
+				s.SELinuxLabel = "disabled"
+			}
+		}
+	}
+	// privileged containers are not confined by seccomp
+	if s.Privileged {
+		s.SeccompProfile = seccompUnconfined
+	}
+
+	if json.Config != nil {
+		s.User = json.Config.User
+	}
+	s.RunsAsRoot = isRootUser(s.User)
+
+	for _, m := range json.Mounts {
+		if m.Type != "bind" {
+			continue
+		}
+		if _, ok := sensitiveHostPaths[filepath.Clean(m.Source)]; ok {
+			s.SensitiveMounts = append(s.SensitiveMounts, m.Source)
+		}
+	}
+	sort.Strings(s.SensitiveMounts)
+
+	s.RiskScore = riskScore(s)
+
+	return s
+}
+
+// isRootUser returns true if the container user is root, which is the case when no user is set.
+func isRootUser(user string) bool {
+	name, _, _ := strings.Cut(user, ":")
+	return name == "" || name == "root" || name == "0"
+}
+
+// normalizeCapabilities removes the optional "CAP_" prefix from the capabilities so they can be compared.
+func normalizeCapabilities(caps []string) []string {
+	if len(caps) == 0 {
+		return nil
+	}
+	normalized := make([]string, 0, len(caps))
+	for _, c := range caps {
+		normalized = append(normalized, strings.TrimPrefix(strings.ToUpper(c), "CAP_"))
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// riskScore computes a score from 0 to 100 representing how much the container weakens the host isolation.
+// nolint: mnd
+func riskScore(s *Security) int {
+	score := 0
+	if s.Privileged {
+		score += 40
+	}
+	for _, c := range s.CapAdd {
+		if _, ok := dangerousCapabilities[c]; ok {
+			score += 10
+		}
+	}
+	if s.SeccompProfile == seccompUnconfined {
+		score += 10
+	}
+	if s.AppArmorProfile == "unconfined" {
+		score += 5
+	}
+	if s.SELinuxLabel == "disabled" {
+		score += 5
+	}
+	if !s.ReadonlyRootfs {
+		score += 5
+	}
+	if s.RunsAsRoot {
+		score += 10
+	}
+	if s.HostPID {
+		score += 10
+	}
+	if s.HostIPC {
+		score += 5
+	}
+	if s.HostNetwork {
+		score += 10
+	}
+	score += 20 * len(s.SensitiveMounts)
+
+	if score > maxRiskScore {
+		return maxRiskScore
+	}
+	return score
+}
@@ -0,0 +1,116 @@
+package biz
+
+import (
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsFetcher_Security(t *testing.T) {
+	tests := []struct {
+		name string
+		json container.InspectResponse
+		want *Security
+	}{
+		{
+			name: "missing host config",
+			json: container.InspectResponse{ID: "abc"},
+			want: nil,
+		},
+		{
+			name: "fargate container",
+			json: container.InspectResponse{
+				ID:         "abc",
+				HostConfig: &container.HostConfig{},
+				Config: &container.Config{
+					Labels: map[string]string{"com.newrelic.nri-docker.launch-type": "fargate"},
+				},
+			},
+			want: nil,
+		},
+		{
+			name: "hardened container",
+			json: container.InspectResponse{
+				ID:              "abc",
+				AppArmorProfile: "docker-default",
+				HostConfig: &container.HostConfig{
+					CapDrop:        []string{"ALL"},
+					ReadonlyRootfs: true,
+					UsernsMode:     "private",
+				},
+				Config: &container.Config{User: "1000:1000"},
+			},
+			want: &Security{
+				CapDrop:         []string{"ALL"},
+				SeccompProfile:  "default",
+				AppArmorProfile: "docker-default",
+				ReadonlyRootfs:  true,
+				User:            "1000:1000",
+				UsernsMode:      "private",
+			},
+		},
+		{
+			name: "over-capable container",
+			json: container.InspectResponse{
+				ID: "abc",
+				HostConfig: &container.HostConfig{
+					Privileged:  true,
+					CapAdd:      []string{"cap_sys_admin", "NET_RAW"},
+					PidMode:     "host",
+					IpcMode:     "host",
+					NetworkMode: "host",
+					SecurityOpt: []string{"apparmor=unconfined", "label=disable"},
+				},
+				Mounts: []container.MountPoint{
+					{Type: "bind", Source: "/var/run/docker.sock", Destination: "/var/run/docker.sock"},
+					{Type: "bind", Source: "/", Destination: "/host"},
+					{Type: "bind", Source: "/home/user", Destination: "/data"},
+					{Type: "volume", Source: "/proc", Destination: "/proc-volume"},
+				},
+			},
+			want: &Security{
+				Privileged:      true,
+				CapAdd:          []string{"NET_RAW", "SYS_ADMIN"},
+				SeccompProfile:  "unconfined",
+				AppArmorProfile: "unconfined",
+				SELinuxLabel:    "disabled",
+				RunsAsRoot:      true,
+				HostPID:         true,
+				HostIPC:         true,
+				HostNetwork:     true,
+				SensitiveMounts: []string{"/", "/var/run/docker.sock"},
+				RiskScore:       100,
+			},
+		},
+		{
+			name: "custom seccomp profile",
+			json: container.InspectResponse{
+				ID: "abc",
+				HostConfig: &container.HostConfig{
+					SecurityOpt: []string{`seccomp={"defaultAction":"SCMP_ACT_ERRNO"}`},
+				},
+				Config: &container.Config{User: "root"},
+			},
+			want: &Security{
+				SeccompProfile: "custom",
+				User:           "root",
+				RunsAsRoot:     true,
+				RiskScore:      15,
+			},
+		},
+	}
+
+	mc := NewProcessor(nil, nil, nil, 0)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, mc.security(&tt.json))
+		})
+	}
+}
+
+func TestRiskScore(t *testing.T) {
+	assert.Equal(t, 0, riskScore(&Security{ReadonlyRootfs: true}))
+	assert.Equal(t, 15, riskScore(&Security{RunsAsRoot: true}))
+	assert.Equal(t, 100, riskScore(&Security{Privileged: true, SensitiveMounts: []string{"/", "/proc", "/sys"}}))
+}
@@ -1,12 +1,27 @@
 package biz
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
 
-	"github.com/moby/moby/api/types/system"
 	humanize "github.com/dustin/go-humanize"
+	"github.com/moby/moby/api/types/system"
+
+	"github.com/newrelic/nri-docker/src/raw"
+)
+
+const (
+	storageDriverDeviceMapper = "devicemapper"
+	storageDriverOverlay2     = "overlay2"
+	storageDriverOverlayFS    = "overlayfs"
+	storageDriverBtrfs        = "btrfs"
+	storageDriverZFS          = "zfs"
 )
 
+// ErrUnsupportedStorageDriver is returned when no storage stats can be computed for the storage driver in use.
+var ErrUnsupportedStorageDriver = errors.New("unsupported storage driver")
+
 // DeviceMapperStats contains the stats of devicemapper storages driver type
 type DeviceMapperStats struct {
 	DataAvailable        uint64
@@ -19,11 +34,66 @@ type DeviceMapperStats struct {
 	MetadataUsagePercent float64
 }
 
+// InodeStats contains the inode usage of the filesystem backing the storage driver
+type InodeStats struct {
+	Total        uint64
+	Free         uint64
+	Used         uint64
+	UsagePercent float64
+}
+
+// StorageDriverStats contains the stats of the storage driver in use. Metadata stats are only reported by
+// devicemapper and Inodes are only available for drivers whose stats are read from the backing filesystem.
+type StorageDriverStats struct {
+	Driver string
+	DeviceMapperStats
+	HasMetadata bool
+	Inodes      *InodeStats
+}
+
+// FilesystemUsage holds the values returned by statfs for a given path.
+type FilesystemUsage struct {
+	BlockSize       uint64
+	Blocks          uint64
+	BlocksFree      uint64
+	BlocksAvailable uint64
+	Inodes          uint64
+	InodesFree      uint64
+}
+
+type statFSFunc func(path string) (FilesystemUsage, error)
+
+// ParseStorageDriverStats computes the storage stats of the storage driver in use by the Docker daemon.
+// For overlay2 and btrfs, the backing filesystem of the DockerRootDir (located under hostRoot) is inspected.
+// For devicemapper and zfs the stats are parsed from the DriverStatus info array.
+func ParseStorageDriverStats(info system.Info, hostRoot string) (*StorageDriverStats, error) {
+	return parseStorageDriverStats(info, hostRoot, filesystemUsage)
+}
+
+func parseStorageDriverStats(info system.Info, hostRoot string, statFS statFSFunc) (*StorageDriverStats, error) {
+	switch info.Driver {
+	case storageDriverDeviceMapper:
+		dmStats, err := ParseDeviceMapperStats(info)
+		if err != nil {
+			return nil, err
+		}
+		return &StorageDriverStats{Driver: info.Driver, DeviceMapperStats: *dmStats, HasMetadata: true}, nil
+	case storageDriverOverlay2, storageDriverOverlayFS, storageDriverBtrfs:
+		// btrfs DriverStatus only contains the build and library versions, so the same approach
+		// as for overlay2 is followed.
+		return parseBackingFilesystemStats(info, hostRoot, statFS)
+	case storageDriverZFS:
+		return parseZFSStats(info)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedStorageDriver, info.Driver)
+	}
+}
+
 // ParseDeviceMapperStats parses the output from the info DriverStatus info Array. This information is not consider stable by
 // Docker and could change anytime according to API the docs.
 func ParseDeviceMapperStats(info system.Info) (*DeviceMapperStats, error) {
 
-	if info.Driver != "devicemapper" {
+	if info.Driver != storageDriverDeviceMapper {
 		return nil, fmt.Errorf("only devicemapper is supported")
 	}
 
@@ -62,3 +132,77 @@ func ParseDeviceMapperStats(info system.Info) (*DeviceMapperStats, error) {
 
 	return stats, nil
 }
+
+// parseBackingFilesystemStats reports the capacity and inodes of the filesystem where the DockerRootDir is mounted.
+func parseBackingFilesystemStats(info system.Info, hostRoot string, statFS statFSFunc) (*StorageDriverStats, error) {
+	if info.DockerRootDir == "" {
+		return nil, fmt.Errorf("DockerRootDir not found")
+	}
+
+	detectedHostRoot, err := raw.DetectHostRoot(hostRoot, raw.CanAccessDir)
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(detectedHostRoot, info.DockerRootDir)
+	usage, err := statFS(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading filesystem stats of %s: %w", path, err)
+	}
+
+	stats := &StorageDriverStats{Driver: info.Driver}
+	stats.DataTotal = usage.Blocks * usage.BlockSize
+	stats.DataAvailable = usage.BlocksAvailable * usage.BlockSize
+	stats.DataUsed = (usage.Blocks - usage.BlocksFree) * usage.BlockSize
+	if stats.DataTotal != 0 {
+		stats.DataUsagePercent = 100 * float64(stats.DataUsed) / float64(stats.DataTotal)
+	}
+
+	// Some filesystems (e.g. btrfs) report zero inodes since they are allocated dynamically.
+	if usage.Inodes != 0 {
+		stats.Inodes = &InodeStats{
+			Total:        usage.Inodes,
+			Free:         usage.InodesFree,
+			Used:         usage.Inodes - usage.InodesFree,
+			UsagePercent: 100 * float64(usage.Inodes-usage.InodesFree) / float64(usage.Inodes),
+		}
+	}
+
+	return stats, nil
+}
+
+// parseZFSStats parses the zfs DriverStatus info Array. The total space is the parent dataset quota, if any,
+// otherwise it is the sum of the used and available space.
+func parseZFSStats(info system.Info) (*StorageDriverStats, error) {
+	if len(info.DriverStatus) == 0 {
+		return nil, fmt.Errorf("DriverStatus not found")
+	}
+
+	stats := &StorageDriverStats{Driver: info.Driver}
+	var quota uint64
+	for _, status := range info.DriverStatus {
+		value, err := humanize.ParseBytes(status[1])
+		if err != nil {
+			// i.e. "Parent Quota" is "no" when the parent dataset has no quota
+			continue
+		}
+		switch status[0] {
+		case "Space Used By Parent":
+			stats.DataUsed = value
+		case "Space Available":
+			stats.DataAvailable = value
+		case "Parent Quota":
+			quota = value
+		}
+	}
+
+	stats.DataTotal = stats.DataUsed + stats.DataAvailable
+	if quota != 0 {
+		stats.DataTotal = quota
+	}
+	if stats.DataTotal != 0 {
+		stats.DataUsagePercent = 100 * float64(stats.DataUsed) / float64(stats.DataTotal)
+	}
+
+	return stats, nil
+}
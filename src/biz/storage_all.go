@@ -0,0 +1,10 @@
+//go:build !linux
+
+package biz
+
+import "fmt"
+
+// filesystemUsage is only supported on Linux, where overlay2 and btrfs storage drivers are available.
+func filesystemUsage(path string) (FilesystemUsage, error) {
+	return FilesystemUsage{}, fmt.Errorf("filesystem stats are not supported on this platform")
+}
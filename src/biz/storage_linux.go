@@ -0,0 +1,21 @@
+//go:build linux
+
+package biz
+
+import "syscall"
+
+// filesystemUsage returns the statfs values of the filesystem containing the given path.
+func filesystemUsage(path string) (FilesystemUsage, error) {
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(path, &fs); err != nil {
+		return FilesystemUsage{}, err
+	}
+	return FilesystemUsage{
+		BlockSize:       uint64(fs.Bsize),
+		Blocks:          fs.Blocks,
+		BlocksFree:      fs.Bfree,
+		BlocksAvailable: fs.Bavail,
+		Inodes:          fs.Files,
+		InodesFree:      fs.Ffree,
+	}, nil
+}
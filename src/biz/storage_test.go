@@ -1,10 +1,15 @@
 package biz
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
 	"github.com/moby/moby/api/types/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_ParseDeviceMapperStats(t *testing.T) {
@@ -99,3 +104,121 @@ func Test_ParseDeviceMapperStats(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseStorageDriverStats(t *testing.T) {
+	hostRoot := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(hostRoot, "proc"), 0o755))
+
+	statFS := func(path string) (FilesystemUsage, error) {
+		if path != filepath.Join(hostRoot, "/var/lib/docker") {
+			return FilesystemUsage{}, errors.New("unexpected path")
+		}
+		return FilesystemUsage{
+			BlockSize:       4096,
+			Blocks:          1000,
+			BlocksFree:      300,
+			BlocksAvailable: 250,
+			Inodes:          200,
+			InodesFree:      150,
+		}, nil
+	}
+
+	tests := []struct {
+		name    string
+		info    system.Info
+		want    *StorageDriverStats
+		wantErr error
+	}{
+		{
+			name: "overlay2",
+			info: system.Info{
+				Driver:        "overlay2",
+				DockerRootDir: "/var/lib/docker",
+				DriverStatus:  [][2]string{{"Backing Filesystem", "extfs"}},
+			},
+			want: &StorageDriverStats{
+				Driver: "overlay2",
+				DeviceMapperStats: DeviceMapperStats{
+					DataTotal:        4096000,
+					DataAvailable:    1024000,
+					DataUsed:         2867200,
+					DataUsagePercent: 70,
+				},
+				Inodes: &InodeStats{Total: 200, Free: 150, Used: 50, UsagePercent: 25},
+			},
+		},
+		{
+			name: "zfs with quota",
+			info: system.Info{
+				Driver: "zfs",
+				DriverStatus: [][2]string{
+					{"Zpool", "zroot"},
+					{"Space Used By Parent", "1000"},
+					{"Space Available", "3000"},
+					{"Parent Quota", "5000"},
+				},
+			},
+			want: &StorageDriverStats{
+				Driver: "zfs",
+				DeviceMapperStats: DeviceMapperStats{
+					DataUsed:         1000,
+					DataAvailable:    3000,
+					DataTotal:        5000,
+					DataUsagePercent: 20,
+				},
+			},
+		},
+		{
+			name: "zfs without quota",
+			info: system.Info{
+				Driver: "zfs",
+				DriverStatus: [][2]string{
+					{"Space Used By Parent", "1000"},
+					{"Space Available", "3000"},
+					{"Parent Quota", "no"},
+				},
+			},
+			want: &StorageDriverStats{
+				Driver: "zfs",
+				DeviceMapperStats: DeviceMapperStats{
+					DataUsed:         1000,
+					DataAvailable:    3000,
+					DataTotal:        4000,
+					DataUsagePercent: 25,
+				},
+			},
+		},
+		{
+			name: "devicemapper",
+			info: system.Info{
+				Driver:       "devicemapper",
+				DriverStatus: [][2]string{{"Data Space Used", "10"}, {"Data Space Total", "100"}},
+			},
+			want: &StorageDriverStats{
+				Driver: "devicemapper",
+				DeviceMapperStats: DeviceMapperStats{
+					DataUsed:         10,
+					DataTotal:        100,
+					DataUsagePercent: 10,
+				},
+				HasMetadata: true,
+			},
+		},
+		{
+			name:    "not supported driver",
+			info:    system.Info{Driver: "vfs"},
+			wantErr: ErrUnsupportedStorageDriver,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStorageDriverStats(tt.info, hostRoot, statFS)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
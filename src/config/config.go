@@ -1,17 +1,46 @@
 package config
 
-import "github.com/newrelic/infra-integrations-sdk/v3/args"
+import (
+	"fmt"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/args"
+)
 
 type ArgumentList struct {
 	args.DefaultArgumentList
-	HostRoot              string `default:"" help:"If the integration is running from a container, the mounted folder pointing to the host root folder"`
-	Fargate               bool   `default:"false" help:"Enables fetching metrics from ECS Fargate. If enabled no metrics are collected from cgroups. Defaults to false"`
-	UseDockerAPI          bool   `default:"false" help:"Enables fetching metrics from Docker API. If enabled no metrics are collected from cgroups. For Linux: This option is ignored if cgroupsV1 are detected. For OSes other than Linux: This option is always ignored and defaults to true"`
-	ExitedContainersTTL   string `default:"24h" help:"Enables to integration to stop reporting Exited containers that are older than the set TTL. Possible values are time-strings: 1s, 1m, 1h"`
-	DockerClientVersion   string `default:"1.44" help:"Optional. Specify the version of the docker client. Used for compatibility."`
-	DisableStorageMetrics bool   `default:"false" help:"Disables storage driver metrics collection."`
-	ShowVersion           bool   `default:"false" help:"Print build information and exit"`
+	HostRoot                string `default:"" help:"If the integration is running from a container, the mounted folder pointing to the host root folder"`
+	Fargate                 bool   `default:"false" help:"Enables fetching metrics from ECS Fargate. If enabled no metrics are collected from cgroups. Defaults to false"`
+	ECS                     bool   `default:"false" help:"Enables the ECS on EC2 mode, which decorates the containers of ECS tasks with the metadata of the ECS agent introspection API and of the task metadata endpoint. Defaults to false"`
+	ECSAgentURL             string `default:"http://localhost:51678" help:"URL of the ECS agent introspection API, used in the ECS on EC2 mode."`
+	UseDockerAPI            bool   `default:"false" help:"Enables fetching metrics from Docker API. If enabled no metrics are collected from cgroups. For Linux: This option is ignored if cgroupsV1 are detected. For OSes other than Linux: This option is always ignored and defaults to true"`
+	ExitedContainersTTL     string `default:"24h" help:"Enables to integration to stop reporting Exited containers that are older than the set TTL. Possible values are time-strings: 1s, 1m, 1h"`
+	DockerClientVersion     string `default:"" help:"Optional. Docker API version to use, e.g. 1.44. By default it is negotiated with the daemon."`
+	DockerHost              string `default:"" help:"Optional. Docker daemon URL, e.g. unix:///var/run/docker.sock, tcp://10.0.0.1:2376 or ssh://user@host. Defaults to the DOCKER_HOST environment variable or the local socket."`
+	DockerContext           string `default:"" help:"Optional. Name of the Docker CLI context, stored in ~/.docker/contexts, to connect to. Defaults to the DOCKER_CONTEXT environment variable. Explicit connection arguments take precedence over the context ones."`
+	DockerTLSCaCert         string `default:"" help:"Optional. Path to the CA certificate used to verify the Docker daemon certificate."`
+	DockerTLSCert           string `default:"" help:"Optional. Path to the client certificate used to connect to the Docker daemon."`
+	DockerTLSKey            string `default:"" help:"Optional. Path to the client key used to connect to the Docker daemon."`
+	DockerTLSSkipVerify     bool   `default:"false" help:"Disables the Docker daemon certificate verification. Not recommended."`
+	DisableStorageMetrics   bool   `default:"false" help:"Disables storage driver metrics collection."`
+	DisableInventory        bool   `default:"false" help:"Disables reporting the containers configuration as inventory."`
+	InventoryEnvValues      string `default:"hide" help:"How environment variable values are reported in the containers inventory. Possible values: hide, redact-sensitive, show"`
+	SecurityRiskScore       bool   `default:"false" help:"Enables reporting a computed securityRiskScore attribute from the containers security configuration."`
+	SkipKubernetesSandboxes bool   `default:"false" help:"Skips reporting the sandbox (pause) containers of Kubernetes pods created by cri-dockerd."`
+	ConfigFile              string `default:"" help:"Optional. Path to a YAML configuration file. It can set any of the other arguments, which take precedence over the file, plus structured settings such as the labels reporting rules."`
+	ShowVersion             bool   `default:"false" help:"Print build information and exit"`
 	// CgroupPath and CgroupDriver arguments are not used but are kept here for backwards compatibility reasons.
 	CgroupPath   string `default:"" help:"Deprecated. cgroup_path argument is not used anymore."`
 	CgroupDriver string `default:"" help:"Deprecated. cgroup_driver argument is not used anymore."`
 }
+
+// Validate checks the values of the arguments which are not validated when parsed.
+func (args *ArgumentList) Validate() error {
+	if _, err := time.ParseDuration(args.ExitedContainersTTL); err != nil {
+		return fmt.Errorf("invalid exited_containers_ttl %q: %w", args.ExitedContainersTTL, err)
+	}
+	if args.Fargate && args.ECS {
+		return fmt.Errorf("fargate and ecs arguments cannot be enabled at the same time")
+	}
+	return nil
+}
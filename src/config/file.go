@@ -0,0 +1,181 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const configFileArg = "config_file"
+
+// File holds the settings of the YAML file set in the config_file argument. Besides the structured sections,
+// any integration argument can be set using its name (e.g. exited_containers_ttl), either at the top level or
+// inside an `env` block with the same format as the infra agent integrations configuration.
+type File struct {
+	Labels    Labels    `yaml:"labels"`
+	Redaction Redaction `yaml:"redaction"`
+	// Daemons lists the Docker daemons to monitor. If empty, the daemon set in the arguments is monitored.
+	Daemons []Daemon `yaml:"daemons"`
+	// Env holds integration arguments, keyed by their environment variable or argument name.
+	Env map[string]string `yaml:"env"`
+	// Options holds the integration arguments set at the top level of the file.
+	Options map[string]string `yaml:",inline"`
+}
+
+// Labels configures how container labels are reported as attributes. The zero value reports every label
+// as a `label.<key>` attribute, plus the built-in renamed attributes.
+type Labels struct {
+	// Rename defines additional label to attribute mappings, on top of the built-in ones.
+	Rename []LabelRename `yaml:"rename"`
+	// Include is a list of regular expressions. If set, only the labels whose key matches any of them are
+	// reported as `label.<key>` attributes.
+	Include []string `yaml:"include"`
+	// Exclude is a list of regular expressions. Labels whose key matches any of them are not reported as
+	// `label.<key>` attributes.
+	Exclude []string `yaml:"exclude"`
+	// MaxCount is the maximum number of `label.<key>` attributes reported for each container. Zero means no limit.
+	MaxCount int `yaml:"max_count"`
+	// MaxValueLength is the length at which the values of the `label.<key>` and renamed attributes are truncated.
+	// Zero means no limit.
+	MaxValueLength int `yaml:"max_value_length"`
+}
+
+// LabelRename reports the labels whose key matches the Match regular expression as the Attribute attribute.
+// Attribute can reference the capture groups of Match, e.g. `$1` or `${name}`.
+type LabelRename struct {
+	Match     string `yaml:"match"`
+	Attribute string `yaml:"attribute"`
+}
+
+// Daemon defines the connection to one of the monitored Docker daemons, with the same meaning as the
+// docker_* arguments.
+type Daemon struct {
+	Host          string `yaml:"host"`
+	Context       string `yaml:"context"`
+	TLSCaCert     string `yaml:"tls_ca_cert"`
+	TLSCert       string `yaml:"tls_cert"`
+	TLSKey        string `yaml:"tls_key"`
+	TLSSkipVerify bool   `yaml:"tls_skip_verify"`
+	// UseDockerAPI fetches the metrics of this daemon from the Docker API instead of cgroups, e.g. for
+	// Docker-in-Docker daemons whose container PIDs are not visible from the host.
+	UseDockerAPI bool `yaml:"use_docker_api"`
+}
+
+// Redaction configures the secrets redaction applied to the container command lines, labels and inventory.
+type Redaction struct {
+	// Patterns are regular expressions matching secrets, on top of the built-in ones. If a pattern has a capture
+	// group named "secret", only that group is redacted; otherwise the whole match is.
+	Patterns []string `yaml:"patterns"`
+}
+
+// LoadFile reads the YAML configuration file in the given path. An empty path returns an empty File.
+func LoadFile(path string) (File, error) {
+	file := File{}
+	if path == "" {
+		return file, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return file, fmt.Errorf("reading config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return file, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	for idx, d := range file.Daemons {
+		if d.Host == "" && d.Context == "" {
+			return file, fmt.Errorf("parsing config file %s: daemon %d has no host nor context", path, idx)
+		}
+	}
+
+	return file, nil
+}
+
+// FilePath returns the path of the config file set in the given command line arguments, or in the environment
+// otherwise. It is needed before the arguments are parsed, so the file can be applied as their defaults.
+func FilePath(args []string) string {
+	for idx, arg := range args {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != configFileArg {
+			continue
+		}
+		if !hasValue && idx+1 < len(args) {
+			value = args[idx+1]
+		}
+		return value
+	}
+	return os.Getenv(strings.ToUpper(configFileArg))
+}
+
+// SetFileEnv sets the integration arguments defined in the config file which are not set in the environment as
+// environment variables, so the integration arguments, including the SDK ones such as verbose or pretty, take
+// them as defaults when they are parsed. Command line flags take precedence over them.
+func SetFileEnv(file File) error {
+	for name, value := range fileOptions(file) {
+		envName := strings.ToUpper(name)
+		if os.Getenv(envName) != "" {
+			continue
+		}
+		if err := os.Setenv(envName, value); err != nil {
+			return fmt.Errorf("config file: setting option %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ApplyFile sets the integration arguments defined in the config file into the given flag set, validating them.
+// Arguments already set as command line flags or environment variables take precedence over the file, except the
+// environment variables set by SetFileEnv, which hold the same values.
+func ApplyFile(fs *flag.FlagSet, file File) error {
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	options := fileOptions(file)
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if name == configFileArg {
+			return fmt.Errorf("config file: %s can't be set in the config file", configFileArg)
+		}
+		if fs.Lookup(name) == nil {
+			return fmt.Errorf("config file: unknown option %q", name)
+		}
+		if env := os.Getenv(strings.ToUpper(name)); explicit[name] || (env != "" && env != options[name]) {
+			continue
+		}
+		if err := fs.Set(name, options[name]); err != nil {
+			return fmt.Errorf("config file: invalid value %q for option %s: %w", options[name], name, err)
+		}
+	}
+
+	return nil
+}
+
+// fileOptions returns the integration arguments of the config file, keyed by their lowercased name. Top level
+// options take precedence over the ones in the env block.
+func fileOptions(file File) map[string]string {
+	options := map[string]string{}
+	for _, values := range []map[string]string{file.Env, file.Options} {
+		for key, value := range values {
+			options[strings.ToLower(key)] = value
+		}
+	}
+	return options
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nri-docker.yml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+labels:
+  rename:
+    - match: ^com\.acme\.(.+)$
+      attribute: acme_$1
+  exclude:
+    - ^org\.opencontainers\.
+  max_count: 20
+  max_value_length: 256
+redaction:
+  patterns:
+    - AKIA[0-9A-Z]{16}
+daemons:
+  - host: unix:///var/run/docker.sock
+  - host: tcp://10.0.0.1:2376
+    tls_ca_cert: /etc/docker/ca.pem
+    use_docker_api: true
+  - context: dind
+`), 0o600))
+
+	file, err := LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, Labels{
+		Rename:         []LabelRename{{Match: `^com\.acme\.(.+)$`, Attribute: "acme_$1"}},
+		Exclude:        []string{`^org\.opencontainers\.`},
+		MaxCount:       20,
+		MaxValueLength: 256,
+	}, file.Labels)
+	assert.Equal(t, Redaction{Patterns: []string{"AKIA[0-9A-Z]{16}"}}, file.Redaction)
+	assert.Equal(t, []Daemon{
+		{Host: "unix:///var/run/docker.sock"},
+		{Host: "tcp://10.0.0.1:2376", TLSCaCert: "/etc/docker/ca.pem", UseDockerAPI: true},
+		{Context: "dind"},
+	}, file.Daemons)
+}
+
+func TestLoadFileEmpty(t *testing.T) {
+	file, err := LoadFile("")
+	require.NoError(t, err)
+	assert.Equal(t, File{}, file)
+
+	path := filepath.Join(t.TempDir(), "empty.yml")
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+	file, err = LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, File{}, file)
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	_, err := LoadFile(filepath.Join(t.TempDir(), "missing.yml"))
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "unknown.yml")
+	require.NoError(t, os.WriteFile(path, []byte("labels:\n  max_labels: 1\n"), 0o600))
+	_, err = LoadFile(path)
+	assert.Error(t, err, "unknown fields are rejected")
+
+	path = filepath.Join(t.TempDir(), "daemons.yml")
+	require.NoError(t, os.WriteFile(path, []byte("daemons:\n  - tls_skip_verify: true\n"), 0o600))
+	_, err = LoadFile(path)
+	assert.ErrorContains(t, err, "daemon 0 has no host nor context")
+}
+
+func TestApplyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nri-docker.yml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+exited_containers_ttl: 1h
+disable_inventory: true
+host_root: /from-file
+env:
+  HOST_ROOT: /from-env-block
+  INVENTORY_ENV_VALUES: show
+  SECURITY_RISK_SCORE: true
+`), 0o600))
+	file, err := LoadFile(path)
+	require.NoError(t, err)
+
+	args := ArgumentList{}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.StringVar(&args.ExitedContainersTTL, "exited_containers_ttl", "24h", "")
+	fs.BoolVar(&args.DisableInventory, "disable_inventory", false, "")
+	fs.StringVar(&args.HostRoot, "host_root", "", "")
+	fs.StringVar(&args.InventoryEnvValues, "inventory_env_values", "hide", "")
+	fs.BoolVar(&args.SecurityRiskScore, "security_risk_score", false, "")
+	require.NoError(t, fs.Parse([]string{"-exited_containers_ttl=5m"}))
+	t.Setenv("INVENTORY_ENV_VALUES", "redact-sensitive")
+	args.InventoryEnvValues = "redact-sensitive"
+
+	require.NoError(t, ApplyFile(fs, file))
+
+	assert.Equal(t, "5m", args.ExitedContainersTTL, "command line flags take precedence")
+	assert.Equal(t, "redact-sensitive", args.InventoryEnvValues, "environment variables take precedence")
+	assert.Equal(t, "/from-file", args.HostRoot, "top level options take precedence over the env block")
+	assert.True(t, args.DisableInventory)
+	assert.True(t, args.SecurityRiskScore)
+}
+
+func TestApplyFileErrors(t *testing.T) {
+	args := ArgumentList{}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.BoolVar(&args.DisableInventory, "disable_inventory", false, "")
+	fs.StringVar(&args.ConfigFile, "config_file", "", "")
+
+	for name, file := range map[string]File{
+		"unknown option": {Options: map[string]string{"disable_inventori": "true"}},
+		"invalid value":  {Env: map[string]string{"DISABLE_INVENTORY": "maybe"}},
+		"config file":    {Options: map[string]string{"config_file": "other.yml"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, ApplyFile(fs, file))
+		})
+	}
+}
+
+func TestFilePath(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "/from-env.yml")
+
+	for name, tc := range map[string]struct {
+		args     []string
+		expected string
+	}{
+		"separate value":   {args: []string{"-verbose", "-config_file", "/a.yml"}, expected: "/a.yml"},
+		"inline value":     {args: []string{"--config_file=/b.yml", "-pretty"}, expected: "/b.yml"},
+		"not a flag":       {args: []string{"-host_root", "config_file"}, expected: "/from-env.yml"},
+		"from environment": {args: []string{"-verbose"}, expected: "/from-env.yml"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, FilePath(tc.args))
+		})
+	}
+}
+
+func TestSetFileEnv(t *testing.T) {
+	// registers the variables to be restored after the test
+	for _, name := range []string{"VERBOSE", "PRETTY", "HOST_ROOT", "DISABLE_INVENTORY"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("PRETTY", "false")
+
+	file := File{
+		Options: map[string]string{"verbose": "true", "pretty": "true", "host_root": "/from-file"},
+		Env:     map[string]string{"HOST_ROOT": "/from-env-block", "DISABLE_INVENTORY": "maybe"},
+	}
+	require.NoError(t, SetFileEnv(file))
+
+	assert.Equal(t, "true", os.Getenv("VERBOSE"))
+	assert.Equal(t, "false", os.Getenv("PRETTY"), "environment variables take precedence")
+	assert.Equal(t, "/from-file", os.Getenv("HOST_ROOT"), "top level options take precedence over the env block")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Bool("verbose", false, "")
+	fs.Bool("pretty", false, "")
+	fs.String("host_root", "", "")
+	fs.Bool("disable_inventory", false, "")
+	assert.Error(t, ApplyFile(fs, file), "the values set in the environment are validated")
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, (&ArgumentList{ExitedContainersTTL: "24h"}).Validate())
+	assert.Error(t, (&ArgumentList{ExitedContainersTTL: "one day"}).Validate())
+	assert.Error(t, (&ArgumentList{ExitedContainersTTL: "24h", Fargate: true, ECS: true}).Validate())
+}
@@ -4,6 +4,7 @@
 package main
 
 import (
+	"flag"
 	"os"
 
 	"github.com/newrelic/infra-integrations-sdk/v3/integration"
@@ -19,6 +20,11 @@ var (
 )
 
 func main() {
+	// the config file is loaded before the arguments are parsed, so its options are honoured by the SDK ones too
+	configFile, err := config.LoadFile(config.FilePath(os.Args[1:]))
+	driver.ExitOnErr(err)
+	driver.ExitOnErr(config.SetFileEnv(configFile))
+
 	args := config.ArgumentList{}
 	// we always use the docker API for OSes other than Linux
 	args.UseDockerAPI = driver.ForceTrueForOSOtherThanLinux(args.UseDockerAPI)
@@ -30,12 +36,15 @@ func main() {
 		os.Exit(0)
 	}
 
+	driver.ExitOnErr(config.ApplyFile(flag.CommandLine, configFile))
+	driver.ExitOnErr(args.Validate())
+
 	log.SetupLogging(args.Verbose)
 
 	if args.Fargate {
-		driver.PopulateFromFargate(i, args)
+		driver.PopulateFromFargate(i, args, configFile)
 	} else {
-		driver.PopulateFromDocker(i, args)
+		driver.PopulateFromDocker(i, args, configFile)
 	}
 
 	driver.ExitOnErr(i.Publish())
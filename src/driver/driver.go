@@ -5,15 +5,18 @@ package driver
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"runtime"
 
 	"github.com/moby/moby/api/types/system"
+	"github.com/moby/moby/client"
 	"github.com/newrelic/infra-integrations-sdk/v3/integration"
 	"github.com/newrelic/infra-integrations-sdk/v3/log"
 	"github.com/newrelic/nri-docker/src/config"
 	"github.com/newrelic/nri-docker/src/nri"
+	"github.com/newrelic/nri-docker/src/raw"
 	"github.com/newrelic/nri-docker/src/raw/aws"
 )
 
@@ -38,7 +41,105 @@ func PrintVersion(integrationVersion, gitCommit, buildDate string) {
 		buildDate)
 }
 
-func PopulateFromFargate(i *integration.Integration, args config.ArgumentList) {
+// PopulateFromDocker samples the daemons listed in the configuration file or, if there are none, the daemon set
+// in the arguments. When several daemons are listed, the errors of each of them are logged so the rest are still
+// sampled, and the integration only fails if none of them could be sampled.
+func PopulateFromDocker(i *integration.Integration, args config.ArgumentList, configFile config.File) {
+	err := sampleDaemons(i, args, configFile)
+	if args.ECS {
+		// logged before exiting, as the ECS endpoint errors are the most relevant when sampling failed
+		aws.LogMetadataErrors()
+	}
+	ExitOnErr(err)
+}
+
+func sampleDaemons(i *integration.Integration, args config.ArgumentList, configFile config.File) error {
+	if len(configFile.Daemons) == 0 {
+		return sampleDaemon(i, args, configFile, argsConnection(args), args.UseDockerAPI, false)
+	}
+
+	failed := 0
+	for _, daemon := range configFile.Daemons {
+		conn := daemonConnection(daemon)
+		if err := sampleDaemon(i, args, configFile, conn, args.UseDockerAPI || daemon.UseDockerAPI, true); err != nil {
+			log.Error("sampling Docker daemon %s: %v", daemonName(daemon), err)
+			failed++
+		}
+	}
+	if failed == len(configFile.Daemons) {
+		return errors.New("none of the configured Docker daemons could be sampled")
+	}
+	return nil
+}
+
+// argsConnection returns the connection to the daemon set in the arguments.
+func argsConnection(args config.ArgumentList) raw.DockerConnection {
+	return raw.DockerConnection{
+		Host:          args.DockerHost,
+		Context:       args.DockerContext,
+		TLSCACert:     args.DockerTLSCaCert,
+		TLSCert:       args.DockerTLSCert,
+		TLSKey:        args.DockerTLSKey,
+		TLSSkipVerify: args.DockerTLSSkipVerify,
+	}
+}
+
+// daemonConnection returns the connection to a daemon listed in the configuration file.
+func daemonConnection(daemon config.Daemon) raw.DockerConnection {
+	return raw.DockerConnection{
+		Host:          daemon.Host,
+		Context:       daemon.Context,
+		TLSCACert:     daemon.TLSCaCert,
+		TLSCert:       daemon.TLSCert,
+		TLSKey:        daemon.TLSKey,
+		TLSSkipVerify: daemon.TLSSkipVerify,
+	}
+}
+
+func daemonName(daemon config.Daemon) string {
+	if daemon.Host != "" {
+		return daemon.Host
+	}
+	return "of context " + daemon.Context
+}
+
+// newDaemonClient returns a client connected to the daemon, along with the daemon host. The API version is
+// negotiated with the daemon unless the docker_client_version argument is set.
+func newDaemonClient(args config.ArgumentList, conn raw.DockerConnection) (*raw.DockerClientWrapper, string, error) {
+	conn, err := conn.Resolve()
+	if err != nil {
+		return nil, "", err
+	}
+	dockerClient, err := raw.NewDockerClient(conn, client.WithVersion(args.DockerClientVersion))
+	if err != nil {
+		return nil, "", err
+	}
+
+	docker := raw.NewDockerClientWrapper(dockerClient)
+	apiVersion, err := docker.NegotiateAPIVersion(context.Background())
+	if err != nil {
+		_ = docker.Close()
+		return nil, "", fmt.Errorf("connecting to the Docker daemon at %s: %w", conn.Host, err)
+	}
+	log.Info("connected to the Docker daemon at %s using API version %s", conn.Host, apiVersion)
+
+	return docker, conn.Host, nil
+}
+
+// samplerClient returns the client used to list and inspect the containers of the daemon, which decorates them
+// with the metadata of the ECS agent and tasks in the ECS on EC2 mode.
+func samplerClient(args config.ArgumentList, docker *raw.DockerClientWrapper) (raw.DockerClient, error) {
+	if !args.ECS {
+		return docker, nil
+	}
+	agent, err := aws.NewECSAgentClient(args.ECSAgentURL)
+	if err != nil {
+		return nil, err
+	}
+	return aws.NewECSInspector(docker, agent), nil
+}
+
+func PopulateFromFargate(i *integration.Integration, args config.ArgumentList, configFile config.File) {
 	metadataBaseURL, err := aws.GetMetadataBaseURL()
 	ExitOnErr(err)
 
@@ -48,8 +149,10 @@ func PopulateFromFargate(i *integration.Integration, args config.ArgumentList) {
 	fargateDockerClient, err := aws.NewFargateInspector(metadataBaseURL)
 	ExitOnErr(err)
 
-	sampler, err := nri.NewSampler(fargateFetcher, fargateDockerClient, args)
+	sampler, err := nri.NewSampler(fargateFetcher, fargateDockerClient, args, configFile, nri.Daemon{})
 	ExitOnErr(err)
 	// Info is currently used to get the Storage Driver stats that is not present on Fargate.
-	ExitOnErr(sampler.SampleAll(context.Background(), i, system.Info{}))
+	err = sampler.SampleAll(context.Background(), i, system.Info{})
+	aws.LogMetadataErrors()
+	ExitOnErr(err)
 }
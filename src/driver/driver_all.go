@@ -7,10 +7,10 @@ package driver
 
 import (
 	"context"
+	"fmt"
 	"runtime"
 
 	"github.com/moby/moby/api/types/system"
-	"github.com/moby/moby/client"
 	"github.com/newrelic/infra-integrations-sdk/v3/integration"
 	"github.com/newrelic/nri-docker/src/config"
 	"github.com/newrelic/nri-docker/src/nri"
@@ -23,18 +23,36 @@ func ForceTrueForOSOtherThanLinux(dockerAPIArg bool) bool {
 	return true
 }
 
-func PopulateFromDocker(i *integration.Integration, args config.ArgumentList) {
-	withVersionOpt := client.WithVersion(args.DockerClientVersion)
-	dockerClient, err := client.NewClientWithOpts(client.FromEnv, withVersionOpt)
-	ExitOnErr(err)
-
-	docker := raw.NewDockerClientWrapper(dockerClient)
+// sampleDaemon samples the containers of the daemon, always through the Docker API. If identify is set, the samples
+// are decorated with the daemon host and ID, and the metrics between executions are stored apart from the ones of
+// other daemons.
+func sampleDaemon(i *integration.Integration, args config.ArgumentList, configFile config.File, conn raw.DockerConnection, _, identify bool) error {
+	docker, host, err := newDaemonClient(args, conn)
+	if err != nil {
+		return err
+	}
 	defer docker.Close()
 
+	var daemon nri.Daemon
+	if identify {
+		info, err := docker.Info(context.Background())
+		if err != nil {
+			return fmt.Errorf("connecting to the Docker daemon at %s: %w", host, err)
+		}
+		daemon = nri.Daemon{Host: host, ID: info.ID}
+	}
+
 	fetcher := dockerapi.NewFetcher(docker, runtime.GOOS)
 
-	sampler, err := nri.NewSampler(fetcher, docker, args)
-	ExitOnErr(err)
+	samplerDocker, err := samplerClient(args, docker)
+	if err != nil {
+		return err
+	}
+
+	sampler, err := nri.NewSampler(fetcher, samplerDocker, args, configFile, daemon)
+	if err != nil {
+		return err
+	}
 	// always use dockerAPI if not on Linux
-	ExitOnErr(sampler.SampleAll(context.Background(), i, system.Info{}))
+	return sampler.SampleAll(context.Background(), i, system.Info{})
 }
@@ -5,8 +5,8 @@ package driver
 
 import (
 	"context"
+	"fmt"
 
-	"github.com/moby/moby/client"
 	"github.com/newrelic/infra-integrations-sdk/v3/integration"
 	"github.com/newrelic/infra-integrations-sdk/v3/log"
 	"github.com/newrelic/nri-docker/src/config"
@@ -32,26 +32,43 @@ func UseDockerAPI(dockerAPIRequested bool, version string) bool {
 	return false
 }
 
-func PopulateFromDocker(i *integration.Integration, args config.ArgumentList) {
-	withVersionOpt := client.WithVersion(args.DockerClientVersion)
-	dockerClient, err := client.NewClientWithOpts(client.FromEnv, withVersionOpt)
-	ExitOnErr(err)
-
-	docker := raw.NewDockerClientWrapper(dockerClient)
+// sampleDaemon samples the containers of the daemon. If identify is set, the samples are decorated with the
+// daemon host and ID, and the metrics between executions are stored apart from the ones of other daemons.
+func sampleDaemon(i *integration.Integration, args config.ArgumentList, configFile config.File, conn raw.DockerConnection, useDockerAPI, identify bool) error {
+	docker, host, err := newDaemonClient(args, conn)
+	if err != nil {
+		return err
+	}
 	defer docker.Close()
 
 	cgroupInfo, err := docker.Info(context.Background())
-	ExitOnErr(err)
+	if err != nil {
+		return fmt.Errorf("connecting to the Docker daemon at %s: %w", host, err)
+	}
 
 	var fetcher raw.Fetcher
-	if UseDockerAPI(args.UseDockerAPI, cgroupInfo.CgroupVersion) {
+	if UseDockerAPI(useDockerAPI, cgroupInfo.CgroupVersion) {
 		fetcher = dockerapi.NewFetcher(docker, constants.LinuxPlatformName)
 	} else { // use cgroups as source of data
 		fetcher, err = raw.NewCgroupFetcher(args.HostRoot, cgroupInfo)
-		ExitOnErr(err)
+		if err != nil {
+			return err
+		}
 	}
 
-	sampler, err := nri.NewSampler(fetcher, docker, args)
-	ExitOnErr(err)
-	ExitOnErr(sampler.SampleAll(context.Background(), i, cgroupInfo))
+	var daemon nri.Daemon
+	if identify {
+		daemon = nri.Daemon{Host: host, ID: cgroupInfo.ID}
+	}
+
+	samplerDocker, err := samplerClient(args, docker)
+	if err != nil {
+		return err
+	}
+
+	sampler, err := nri.NewSampler(fetcher, samplerDocker, args, configFile, daemon)
+	if err != nil {
+		return err
+	}
+	return sampler.SampleAll(context.Background(), i, cgroupInfo)
 }
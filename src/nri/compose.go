@@ -0,0 +1,37 @@
+package nri
+
+import (
+	"strings"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/attribute"
+)
+
+const (
+	composeServiceSampleName = "ComposeServiceSample"
+
+	composeProjectLabel         = "com.docker.compose.project"
+	composeServiceLabel         = "com.docker.compose.service"
+	composeContainerNumberLabel = "com.docker.compose.container-number"
+	composeOneoffLabel          = "com.docker.compose.oneoff"
+
+	attrComposeProject = "composeProject"
+	attrComposeService = "composeService"
+)
+
+// composeServiceGroup groups the replicas of Docker Compose services. One-off containers (i.e. created with
+// `docker compose run`) are not considered replicas of the service.
+var composeServiceGroup = containerGroup{
+	eventType: composeServiceSampleName,
+	total:     metricTotalReplicas,
+	running:   metricRunningReplicas,
+	attributes: func(labels map[string]string) []attribute.Attribute {
+		project, service := labels[composeProjectLabel], labels[composeServiceLabel]
+		if project == "" || service == "" || strings.EqualFold(labels[composeOneoffLabel], "true") {
+			return nil
+		}
+		return []attribute.Attribute{
+			attribute.Attr(attrComposeProject, project),
+			attribute.Attr(attrComposeService, service),
+		}
+	},
+}
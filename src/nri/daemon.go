@@ -0,0 +1,85 @@
+package nri
+
+import (
+	"regexp"
+
+	"github.com/moby/moby/api/types/system"
+	"github.com/newrelic/infra-integrations-sdk/v3/data/attribute"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+
+	"github.com/newrelic/nri-docker/src/raw"
+)
+
+const (
+	attrDockerHost = "dockerHost"
+	attrDaemonID   = "daemonId"
+
+	storeName = "container_cpus"
+
+	daemonSampleName = "DockerDaemonSample"
+)
+
+var unsafeStoreChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// Daemon identifies the sampled Docker daemon when several of them are monitored. The zero value is used when
+// a single daemon is monitored, so its samples are not decorated.
+type Daemon struct {
+	Host string
+	ID   string
+}
+
+// attributes returns the attributes added to the samples of the daemon, so samples from different daemons
+// never collide.
+func (d Daemon) attributes() []attribute.Attribute {
+	var attributes []attribute.Attribute
+	if d.Host != "" {
+		attributes = append(attributes, attribute.Attr(attrDockerHost, d.Host))
+	}
+	if d.ID != "" {
+		attributes = append(attributes, attribute.Attr(attrDaemonID, d.ID))
+	}
+	return attributes
+}
+
+// idAttributes returns the attributes identifying the container entities of the daemon, so containers with the
+// same ID in different daemons are reported as different entities.
+func (d Daemon) idAttributes() []integration.IDAttribute {
+	if d.ID == "" {
+		return nil
+	}
+	return []integration.IDAttribute{integration.NewIDAttribute(attrDaemonID, d.ID)}
+}
+
+// storeName returns the name of the file storing the metrics between executions, which is different for each
+// daemon so they don't overwrite each other.
+func (d Daemon) storeName() string {
+	switch {
+	case d.ID != "":
+		return storeName + "_" + unsafeStoreChars.ReplaceAllString(d.ID, "_")
+	case d.Host != "":
+		return storeName + "_" + unsafeStoreChars.ReplaceAllString(d.Host, "_")
+	default:
+		return storeName
+	}
+}
+
+// populateSample creates a DockerDaemonSample in the integration local entity with the API version negotiated
+// with the daemon and, if the daemon info is known, its version and container counts.
+func (d Daemon) populateSample(i *integration.Integration, client raw.VersionedClient, info system.Info) {
+	if client == nil || client.APIVersion() == "" {
+		return
+	}
+
+	ms := i.LocalEntity().NewMetricSet(daemonSampleName, d.attributes()...)
+	populate(ms, []entry{metricAPIVersion(client.APIVersion())})
+	if info.ID == "" {
+		return
+	}
+	populate(ms, []entry{
+		metricDockerVersion(info.ServerVersion),
+		metricContainersRunning(info.ContainersRunning),
+		metricContainersPaused(info.ContainersPaused),
+		metricContainersStopped(info.ContainersStopped),
+		metricImages(info.Images),
+	})
+}
@@ -0,0 +1,186 @@
+package nri
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/attribute"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+
+	"github.com/newrelic/nri-docker/src/biz"
+	"github.com/newrelic/nri-docker/src/raw/aws"
+)
+
+const (
+	ecsTaskSampleName = "EcsTaskSample"
+
+	ecsStatusRunning = "RUNNING"
+	ecsStatusStopped = "STOPPED"
+
+	attrECSTaskArn               = "ecsTaskArn"
+	attrECSClusterName           = "ecsClusterName"
+	attrECSTaskDefinitionFamily  = "ecsTaskDefinitionFamily"
+	attrECSTaskDefinitionVersion = "ecsTaskDefinitionVersion"
+	attrAWSRegion                = "awsRegion"
+	attrAWSAvailabilityZone      = "awsAvailabilityZone"
+	attrAWSAccountID             = "awsAccountId"
+	attrECSServiceName           = "ecsServiceName"
+
+	mebibyte = 1024 * 1024
+)
+
+// ecsTask aggregates the resource usage of the containers of the ECS task the integration runs in.
+type ecsTask struct {
+	metadata     aws.TaskResponse
+	cpuUsedCores float64
+	memoryUsage  uint64
+}
+
+// fetchECSTask returns the ECS task the integration runs in, or nil if the client does not provide task metadata.
+func fetchECSTask(ctx context.Context, client aws.TaskMetadataClient) *ecsTask {
+	if client == nil {
+		return nil
+	}
+	metadata, err := client.TaskMetadata(ctx)
+	if err != nil {
+		log.Warn("fetching ECS task metadata: %v", err)
+		return nil
+	}
+	return &ecsTask{metadata: metadata}
+}
+
+// add accounts the usage of a running container of the task.
+func (t *ecsTask) add(sample *biz.Sample) {
+	if t == nil {
+		return
+	}
+	t.cpuUsedCores += sample.CPU.UsedCores
+	t.memoryUsage += sample.Memory.UsageBytes
+}
+
+// populate creates an EcsTaskSample in the integration local entity, decorated with the given attributes.
+func (t *ecsTask) populate(i *integration.Integration, attributes ...attribute.Attribute) {
+	if t == nil {
+		return
+	}
+
+	ms := i.LocalEntity().NewMetricSet(ecsTaskSampleName, append(t.attributes(), attributes...)...)
+	for _, status := range []entry{metricDesiredStatus(t.metadata.DesiredStatus), metricKnownStatus(t.metadata.KnownStatus)} {
+		if !isAttributeValueEmpty(status) {
+			populate(ms, []entry{status})
+		}
+	}
+	populate(ms, []entry{
+		metricCPUUsedCores(t.cpuUsedCores),
+		metricMemoryUsageBytes(t.memoryUsage),
+	})
+	populate(ms, t.containerCounts())
+
+	if limits := t.metadata.Limits; limits != nil {
+		if limits.CPU != nil && *limits.CPU > 0 {
+			populate(ms, []entry{
+				metricCPULimitCores(*limits.CPU),
+				metricCPUUsedCoresPercent(t.cpuUsedCores / *limits.CPU * 100),
+			})
+		}
+		if limits.Memory != nil && *limits.Memory > 0 {
+			memoryLimit := float64(*limits.Memory * mebibyte)
+			populate(ms, []entry{
+				metricMemorySizeLimitBytes(memoryLimit),
+				metricMemoryUsageLimitPercent(float64(t.memoryUsage) / memoryLimit * 100),
+			})
+		}
+	}
+
+	populate(ms, t.timings(time.Now()))
+
+	if storage := t.metadata.EphemeralStorageMetrics; storage != nil {
+		populate(ms, []entry{
+			metricEphemeralStorageUsed(storage.Utilized * mebibyte),
+			metricEphemeralStorageReserved(storage.Reserved * mebibyte),
+		})
+		if storage.Reserved > 0 {
+			populate(ms, []entry{
+				metricEphemeralStoragePercent(float64(storage.Utilized) / float64(storage.Reserved) * 100),
+			})
+		}
+	}
+}
+
+// timings returns how long the task took to pull its images and to start its containers, and how long it has
+// been alive, from the start of the pull to the end of its execution or to now. Durations whose ends are not
+// reported by the metadata endpoint are omitted.
+func (t *ecsTask) timings(now time.Time) []entry {
+	var entries []entry
+	pullStarted, pullStopped := t.metadata.PullStartedAt, t.metadata.PullStoppedAt
+	if pullStarted != nil && pullStopped != nil {
+		entries = append(entries, metricImagePullDurationSeconds(pullStopped.Sub(*pullStarted).Seconds()))
+	}
+
+	// the task starts when its images start to be pulled, or when its first container is created otherwise
+	started := pullStarted
+	var lastContainerStarted *time.Time
+	for _, c := range t.metadata.Containers {
+		if c.CreatedAt != nil && (started == nil || c.CreatedAt.Before(*started)) {
+			started = c.CreatedAt
+		}
+		if c.StartedAt != nil && (lastContainerStarted == nil || c.StartedAt.After(*lastContainerStarted)) {
+			lastContainerStarted = c.StartedAt
+		}
+	}
+	if started == nil {
+		return entries
+	}
+
+	if lastContainerStarted != nil && !lastContainerStarted.Before(*started) {
+		entries = append(entries, metricStartupDurationSeconds(lastContainerStarted.Sub(*started).Seconds()))
+	}
+	if stopped := t.metadata.ExecutionStoppedAt; stopped != nil {
+		now = *stopped
+	}
+	return append(entries, metricLifetimeSeconds(now.Sub(*started).Seconds()))
+}
+
+// attributes returns the attributes identifying the task, omitting the ones not reported by the metadata endpoint.
+func (t *ecsTask) attributes() []attribute.Attribute {
+	var attributes []attribute.Attribute
+	for _, a := range []struct{ name, value string }{
+		{attrECSTaskArn, t.metadata.TaskARN},
+		{attrECSClusterName, t.metadata.ClusterName()},
+		{attrECSTaskDefinitionFamily, t.metadata.Family},
+		{attrECSTaskDefinitionVersion, t.metadata.Revision},
+		{attrAWSRegion, t.metadata.Region()},
+		{attrAWSAvailabilityZone, t.metadata.AvailabilityZone},
+		{attrAWSAccountID, t.metadata.AccountID()},
+		{attrECSServiceName, t.metadata.ServiceName},
+	} {
+		if a.value != "" {
+			attributes = append(attributes, attribute.Attr(a.name, a.value))
+		}
+	}
+	return attributes
+}
+
+// containerCounts returns the number of containers of the task by their known status. Containers neither running
+// nor stopped are still being provisioned, pulled or created.
+func (t *ecsTask) containerCounts() []entry {
+	var running, stopped, pending int
+	for _, c := range t.metadata.Containers {
+		switch strings.ToUpper(c.KnownStatus) {
+		case ecsStatusRunning:
+			running++
+		case ecsStatusStopped:
+			stopped++
+		default:
+			pending++
+		}
+	}
+	return []entry{
+		metricContainers(len(t.metadata.Containers)),
+		metricContainersRunning(running),
+		metricContainersStopped(stopped),
+		metricContainersPending(pending),
+	}
+}
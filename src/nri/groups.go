@@ -0,0 +1,122 @@
+package nri
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/attribute"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+
+	"github.com/newrelic/nri-docker/src/biz"
+)
+
+// containerGroup defines how containers are grouped into an aggregated sample, e.g. the replicas of a compose service.
+type containerGroup struct {
+	eventType string
+	// attributes returns the attributes identifying the group the container belongs to, given the container labels.
+	// It returns nil if the container does not belong to any group.
+	attributes func(labels map[string]string) []attribute.Attribute
+	// total and running are the metrics counting the containers of the group and the running ones, named after
+	// what the containers are to the group, e.g. replicas or tasks.
+	total, running func(interface{}) entry
+}
+
+// groupUsage holds the aggregated resource usage of the containers of a group.
+type groupUsage struct {
+	attributes     []attribute.Attribute
+	total          int
+	running        int
+	cpuUsedCores   float64
+	cpuLimitCores  float64
+	memoryUsage    uint64
+	memoryRSS      uint64
+	networkRxBytes int64
+	networkTxBytes int64
+	pids           uint64
+	restartCount   int
+}
+
+// groupAggregator aggregates the samples of the containers belonging to a containerGroup.
+type groupAggregator struct {
+	group  containerGroup
+	usages map[string]*groupUsage
+}
+
+func newGroupAggregators(groups ...containerGroup) []*groupAggregator {
+	aggregators := make([]*groupAggregator, 0, len(groups))
+	for _, g := range groups {
+		aggregators = append(aggregators, &groupAggregator{group: g, usages: map[string]*groupUsage{}})
+	}
+	return aggregators
+}
+
+// add accounts the container in its group, if any. Usage is only aggregated for running containers.
+func (ga *groupAggregator) add(labels map[string]string, running bool, sample *biz.Sample) {
+	attributes := ga.group.attributes(labels)
+	if len(attributes) == 0 {
+		return
+	}
+
+	key := groupKey(attributes)
+	usage, ok := ga.usages[key]
+	if !ok {
+		usage = &groupUsage{attributes: attributes}
+		ga.usages[key] = usage
+	}
+
+	usage.total++
+	if !running {
+		return
+	}
+	usage.running++
+	usage.cpuUsedCores += sample.CPU.UsedCores
+	usage.cpuLimitCores += sample.CPU.LimitCores
+	usage.memoryUsage += sample.Memory.UsageBytes
+	usage.memoryRSS += sample.Memory.RSSUsageBytes
+	usage.networkRxBytes += sample.Network.RxBytes
+	usage.networkTxBytes += sample.Network.TxBytes
+	usage.pids += sample.Pids.Current
+	usage.restartCount += sample.RestartCount
+}
+
+// populate creates a sample for each of the groups in the integration local entity, decorated with the given
+// attributes. Percentages and rates can't be added across containers, so the group only reports the ones derived
+// from its own totals.
+func (ga *groupAggregator) populate(i *integration.Integration, attributes ...attribute.Attribute) {
+	keys := make([]string, 0, len(ga.usages))
+	for key := range ga.usages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		usage := ga.usages[key]
+		ms := i.LocalEntity().NewMetricSet(ga.group.eventType, append(usage.attributes, attributes...)...)
+		entries := []entry{
+			ga.group.total(usage.total),
+			ga.group.running(usage.running),
+			metricCPUUsedCores(usage.cpuUsedCores),
+			metricCPULimitCores(usage.cpuLimitCores),
+			metricMemoryUsageBytes(usage.memoryUsage),
+			metricMemoryResidentSizeBytes(usage.memoryRSS),
+			metricRxBytes(usage.networkRxBytes),
+			metricTxBytes(usage.networkTxBytes),
+			metricThreadCount(usage.pids),
+			metricRestartCount(usage.restartCount),
+		}
+		if usage.cpuLimitCores > 0 {
+			entries = append(entries, metricCPUUsedCoresPercent(100*usage.cpuUsedCores/usage.cpuLimitCores))
+		}
+		populate(ms, entries)
+	}
+	log.Debug("reported %d %s samples", len(keys), ga.group.eventType)
+}
+
+func groupKey(attributes []attribute.Attribute) string {
+	parts := make([]string, 0, len(attributes))
+	for _, a := range attributes {
+		parts = append(parts, a.Key+"="+a.Value)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,18 @@
+package nri
+
+import (
+	"strconv"
+
+	"github.com/newrelic/nri-docker/src/raw"
+)
+
+// kubernetes returns the attributes of the containers created by cri-dockerd for Kubernetes pods. The pod
+// metadata itself is reported from the container labels (see labelRename).
+func kubernetes(labels map[string]string) []entry {
+	if !raw.IsKubernetesContainer(labels) {
+		return nil
+	}
+	return []entry{
+		metricK8sSandbox(strconv.FormatBool(raw.IsKubernetesSandbox(labels))),
+	}
+}
@@ -0,0 +1,156 @@
+package nri
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+
+	"github.com/newrelic/nri-docker/src/biz"
+	"github.com/newrelic/nri-docker/src/config"
+	"github.com/newrelic/nri-docker/src/raw/aws"
+)
+
+// tagAttributePrefix prefixes the attributes reporting the ECS task tags, from the synthetic labels prefixed by
+// aws.TagLabelPrefix.
+const tagAttributePrefix = "tag."
+
+type labelRenameRule struct {
+	match     *regexp.Regexp
+	attribute string
+}
+
+// labelMapper turns container labels into attributes. The zero value reports every label as a `label.<key>`
+// attribute, plus the built-in labelRename attributes, without redacting any value.
+type labelMapper struct {
+	rename         []labelRenameRule
+	include        []*regexp.Regexp
+	exclude        []*regexp.Regexp
+	maxCount       int
+	maxValueLength int
+	redactor       *biz.Redactor
+}
+
+func newLabelMapper(cfg config.Labels, redactor *biz.Redactor) (labelMapper, error) {
+	mapper := labelMapper{
+		redactor:       redactor,
+		maxCount:       cfg.MaxCount,
+		maxValueLength: cfg.MaxValueLength,
+	}
+	if cfg.MaxCount < 0 || cfg.MaxValueLength < 0 {
+		return mapper, errors.New("labels max_count and max_value_length can't be negative")
+	}
+
+	for _, r := range cfg.Rename {
+		if r.Attribute == "" {
+			return mapper, fmt.Errorf("label rename rule %q has no attribute", r.Match)
+		}
+		match, err := regexp.Compile(r.Match)
+		if err != nil {
+			return mapper, fmt.Errorf("compiling label rename rule: %w", err)
+		}
+		mapper.rename = append(mapper.rename, labelRenameRule{match: match, attribute: r.Attribute})
+	}
+
+	var err error
+	if mapper.include, err = compileAll(cfg.Include); err != nil {
+		return mapper, fmt.Errorf("compiling labels include list: %w", err)
+	}
+	if mapper.exclude, err = compileAll(cfg.Exclude); err != nil {
+		return mapper, fmt.Errorf("compiling labels exclude list: %w", err)
+	}
+
+	return mapper, nil
+}
+
+// labels returns the attributes for the given container labels. Labels are processed in key order, so the
+// same attributes are kept when the max count is exceeded.
+func (lm labelMapper) labels(labels map[string]string) []entry {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	metrics := make([]entry, 0, len(labels))
+	count := 0
+	for _, key := range keys {
+		val := lm.redactor.RedactKeyValue(key, labels[key])
+
+		// built-in attributes are not truncated, as they hold identifiers other samples are joined on
+		if newName, ok := labelRename[key]; ok {
+			metrics = append(metrics, entry{Name: newName, Value: val, Type: metric.ATTRIBUTE})
+		}
+		if tag, ok := strings.CutPrefix(key, aws.TagLabelPrefix); ok {
+			metrics = append(metrics, entry{Name: tagAttributePrefix + tag, Value: val, Type: metric.ATTRIBUTE})
+		}
+
+		val = lm.truncate(val)
+		if newName, ok := lm.renamed(key); ok {
+			metrics = append(metrics, entry{Name: newName, Value: val, Type: metric.ATTRIBUTE})
+		}
+
+		if !lm.reported(key) {
+			continue
+		}
+		if lm.maxCount > 0 && count >= lm.maxCount {
+			log.Debug("skipping label %q: max label count (%d) reached", key, lm.maxCount)
+			continue
+		}
+		count++
+		metrics = append(metrics, entry{Name: labelPrefix + key, Value: val, Type: metric.ATTRIBUTE})
+	}
+
+	return metrics
+}
+
+// renamed returns the attribute name of the first user rename rule matching the label key.
+func (lm labelMapper) renamed(key string) (string, bool) {
+	for _, rule := range lm.rename {
+		if submatches := rule.match.FindStringSubmatchIndex(key); submatches != nil {
+			return string(rule.match.ExpandString(nil, rule.attribute, key, submatches)), true
+		}
+	}
+	return "", false
+}
+
+// reported returns true if the label key passes the include and exclude lists.
+func (lm labelMapper) reported(key string) bool {
+	if len(lm.include) > 0 && !matchesAny(lm.include, key) {
+		return false
+	}
+	return !matchesAny(lm.exclude, key)
+}
+
+func (lm labelMapper) truncate(val string) string {
+	if lm.maxValueLength > 0 && utf8.RuneCountInString(val) > lm.maxValueLength {
+		return string([]rune(val)[:lm.maxValueLength])
+	}
+	return val
+}
+
+func compileAll(expressions []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(expressions))
+	for _, expr := range expressions {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+func matchesAny(expressions []*regexp.Regexp, s string) bool {
+	for _, re := range expressions {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package nri
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/nri-docker/src/config"
+)
+
+func labelAttributes(entries []entry) map[string]interface{} {
+	attributes := map[string]interface{}{}
+	for _, e := range entries {
+		attributes[e.Name] = e.Value
+	}
+	return attributes
+}
+
+func TestLabelMapperDefaults(t *testing.T) {
+	lm, err := newLabelMapper(config.Labels{}, nil)
+	require.NoError(t, err)
+
+	attributes := labelAttributes(lm.labels(map[string]string{
+		"com.docker.compose.project":     "shop",
+		"org.opencontainers.image.title": "web",
+	}))
+
+	assert.Equal(t, map[string]interface{}{
+		"composeProject":                       "shop",
+		"label.com.docker.compose.project":     "shop",
+		"label.org.opencontainers.image.title": "web",
+	}, attributes)
+}
+
+func TestLabelMapperTaskTags(t *testing.T) {
+	lm, err := newLabelMapper(config.Labels{}, nil)
+	require.NoError(t, err)
+
+	attributes := labelAttributes(lm.labels(map[string]string{
+		"com.newrelic.nri-docker.tag.team":      "payments",
+		"com.newrelic.nri-docker.container-arn": "arn:aws:ecs:us-west-2:111122223333:container/abb51bdd",
+		"com.newrelic.nri-docker.log-group":     "/ecs/api",
+	}))
+
+	assert.Equal(t, "payments", attributes["tag.team"])
+	assert.Equal(t, "arn:aws:ecs:us-west-2:111122223333:container/abb51bdd", attributes["containerArn"])
+	assert.Equal(t, "/ecs/api", attributes["awsLogGroup"])
+}
+
+func TestLabelMapperRules(t *testing.T) {
+	lm, err := newLabelMapper(config.Labels{
+		Rename: []config.LabelRename{
+			{Match: `^com\.acme\.(?P<key>[a-z]+)$`, Attribute: "acme_${key}"},
+			{Match: `^team$`, Attribute: "owner"},
+		},
+		Include:        []string{`^com\.acme\.`, `^team$`, `^com\.docker\.`},
+		Exclude:        []string{`^com\.acme\.secret$`},
+		MaxCount:       2,
+		MaxValueLength: 4,
+	}, nil)
+	require.NoError(t, err)
+
+	attributes := labelAttributes(lm.labels(map[string]string{
+		"com.acme.tier":                  "frontend",
+		"com.acme.secret":                "s3cr3t",
+		"com.amazonaws.ecs.task-arn":     "arn:aws:ecs:us-east-1:123456789012:task/prod/f05a5672",
+		"com.docker.compose.project":     "shop",
+		"org.opencontainers.image.title": "web",
+		"team":                           "payments",
+	}))
+
+	assert.Equal(t, map[string]interface{}{
+		// renamed attributes are not subject to the include/exclude lists nor the max count
+		"acme_tier":      "fron",
+		"acme_secret":    "s3cr",
+		"owner":          "paym",
+		"composeProject": "shop",
+		// built-in attributes are not truncated
+		"ecsTaskArn": "arn:aws:ecs:us-east-1:123456789012:task/prod/f05a5672",
+		// the first two included labels, in key order
+		"label.com.acme.tier":              "fron",
+		"label.com.docker.compose.project": "shop",
+	}, attributes)
+}
+
+func TestNewLabelMapperErrors(t *testing.T) {
+	for name, cfg := range map[string]config.Labels{
+		"invalid rename":    {Rename: []config.LabelRename{{Match: "(", Attribute: "a"}}},
+		"missing attribute": {Rename: []config.LabelRename{{Match: "a"}}},
+		"invalid include":   {Include: []string{"["}},
+		"invalid exclude":   {Exclude: []string{"["}},
+		"negative count":    {MaxCount: -1},
+		"negative length":   {MaxValueLength: -1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := newLabelMapper(cfg, nil)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -58,14 +58,72 @@ var (
 	metricTxDropped                   = metricFunc("networkTxDropped", metric.GAUGE)
 	metricTxErrors                    = metricFunc("networkTxErrors", metric.GAUGE)
 	metricTxPackets                   = metricFunc("networkTxPackets", metric.GAUGE)
-	metricRxBytesPerSecond            = metricFunc("networkRxBytesPerSecond", metric.PRATE)
+	metricRxBytesPerSecond            = metricFunc("networkRxBytesPerSecond", metric.GAUGE)
 	metricRxDroppedPerSecond          = metricFunc("networkRxDroppedPerSecond", metric.PRATE)
 	metricRxErrorsPerSecond           = metricFunc("networkRxErrorsPerSecond", metric.PRATE)
 	metricRxPacketsPerSecond          = metricFunc("networkRxPacketsPerSecond", metric.PRATE)
-	metricTxBytesPerSecond            = metricFunc("networkTxBytesPerSecond", metric.PRATE)
+	metricTxBytesPerSecond            = metricFunc("networkTxBytesPerSecond", metric.GAUGE)
 	metricTxDroppedPerSecond          = metricFunc("networkTxDroppedPerSecond", metric.PRATE)
 	metricTxErrorsPerSecond           = metricFunc("networkTxErrorsPerSecond", metric.PRATE)
 	metricTxPacketsPerSecond          = metricFunc("networkTxPacketsPerSecond", metric.PRATE)
+	metricSwarmServiceName            = metricFunc("swarmServiceName", metric.ATTRIBUTE)
+	metricSwarmServiceMode            = metricFunc("swarmServiceMode", metric.ATTRIBUTE)
+	metricSwarmTaskSlot               = metricFunc("swarmTaskSlot", metric.ATTRIBUTE)
+	metricSwarmTaskState              = metricFunc("swarmTaskState", metric.ATTRIBUTE)
+	metricSwarmTaskDesiredState       = metricFunc("swarmTaskDesiredState", metric.ATTRIBUTE)
+	metricSwarmNodeHostname           = metricFunc("swarmNodeHostname", metric.ATTRIBUTE)
+	metricRedactedValues              = metricFunc("redactedValues", metric.GAUGE)
+	metricK8sSandbox                  = metricFunc("k8sSandbox", metric.ATTRIBUTE)
+	metricDesiredReplicas             = metricFunc("desiredReplicas", metric.GAUGE)
+	metricTotalReplicas               = metricFunc("totalReplicas", metric.GAUGE)
+	metricRunningReplicas             = metricFunc("runningReplicas", metric.GAUGE)
+	metricTotalTasks                  = metricFunc("totalTasks", metric.GAUGE)
+	metricRunningTasks                = metricFunc("runningTasks", metric.GAUGE)
+	metricExposedPorts                = metricFunc("exposedPorts", metric.ATTRIBUTE)
+	metricAPIVersion                  = metricFunc("apiVersion", metric.ATTRIBUTE)
+	metricDockerVersion               = metricFunc("dockerVersion", metric.ATTRIBUTE)
+	metricContainersRunning           = metricFunc("containersRunning", metric.GAUGE)
+	metricContainersPaused            = metricFunc("containersPaused", metric.GAUGE)
+	metricContainersStopped           = metricFunc("containersStopped", metric.GAUGE)
+	metricImages                      = metricFunc("images", metric.GAUGE)
+	metricContainers                  = metricFunc("containers", metric.GAUGE)
+	metricContainersPending           = metricFunc("containersPending", metric.GAUGE)
+	metricDesiredStatus               = metricFunc("desiredStatus", metric.ATTRIBUTE)
+	metricKnownStatus                 = metricFunc("knownStatus", metric.ATTRIBUTE)
+	metricImagePullDurationSeconds    = metricFunc("imagePullDurationSeconds", metric.GAUGE)
+	metricStartLatencySeconds         = metricFunc("startLatencySeconds", metric.GAUGE)
+	metricStartupDurationSeconds      = metricFunc("startupDurationSeconds", metric.GAUGE)
+	metricLifetimeSeconds             = metricFunc("lifetimeSeconds", metric.GAUGE)
+	metricEphemeralStorageUsed        = metricFunc("ephemeralStorageUsedBytes", metric.GAUGE)
+	metricEphemeralStorageReserved    = metricFunc("ephemeralStorageReservedBytes", metric.GAUGE)
+	metricEphemeralStoragePercent     = metricFunc("ephemeralStorageUsagePercent", metric.GAUGE)
+	metricExitCode                    = metricFunc("exitCode", metric.GAUGE)
+	metricFinishedAt                  = metricFunc("finishedAt", metric.ATTRIBUTE)
+	metricStopReason                  = metricFunc("stopReason", metric.ATTRIBUTE)
+	metricPublishedPorts              = metricFunc("publishedPorts", metric.ATTRIBUTE)
+	metricPublished                   = metricFunc("published", metric.ATTRIBUTE)
+	metricPubliclyBound               = metricFunc("publiclyBound", metric.ATTRIBUTE)
+	metricPrivileged                  = metricFunc("privileged", metric.ATTRIBUTE)
+	metricCapAdd                      = metricFunc("capAdd", metric.ATTRIBUTE)
+	metricCapDrop                     = metricFunc("capDrop", metric.ATTRIBUTE)
+	metricSeccompProfile              = metricFunc("seccompProfile", metric.ATTRIBUTE)
+	metricAppArmorProfile             = metricFunc("appArmorProfile", metric.ATTRIBUTE)
+	metricSELinuxLabel                = metricFunc("seLinuxLabel", metric.ATTRIBUTE)
+	metricReadonlyRootfs              = metricFunc("readonlyRootfs", metric.ATTRIBUTE)
+	metricUser                        = metricFunc("user", metric.ATTRIBUTE)
+	metricRunsAsRoot                  = metricFunc("runsAsRoot", metric.ATTRIBUTE)
+	metricHostPID                     = metricFunc("hostPid", metric.ATTRIBUTE)
+	metricHostIPC                     = metricFunc("hostIpc", metric.ATTRIBUTE)
+	metricHostNetwork                 = metricFunc("hostNetwork", metric.ATTRIBUTE)
+	metricUsernsMode                  = metricFunc("usernsMode", metric.ATTRIBUTE)
+	metricSensitiveHostMounts         = metricFunc("sensitiveHostMounts", metric.ATTRIBUTE)
+	metricSecurityRiskScore           = metricFunc("securityRiskScore", metric.GAUGE)
+	metricLogDriver                   = metricFunc("logDriver", metric.ATTRIBUTE)
+	metricLogFilesSizeBytes           = metricFunc("logFilesSizeBytes", metric.GAUGE)
+	metricLogBytesWrittenPerSecond    = metricFunc("logBytesWrittenPerSecond", metric.GAUGE)
+	metricLogMaxSizeBytes             = metricFunc("logMaxSizeBytes", metric.GAUGE)
+	metricLogMaxFiles                 = metricFunc("logMaxFiles", metric.GAUGE)
+	metricStorageDriver               = metricFunc("storageDriver", metric.ATTRIBUTE)
 	metricStorageDataUsed             = metricFunc("storageDataUsedBytes", metric.GAUGE)
 	metricStorageDataAvailable        = metricFunc("storageDataAvailableBytes", metric.GAUGE)
 	metricStorageDataTotal            = metricFunc("storageDataTotalBytes", metric.GAUGE)
@@ -74,6 +132,10 @@ var (
 	metricStorageMetadataAvailable    = metricFunc("storageMetadataAvailableBytes", metric.GAUGE)
 	metricStorageMetadataTotal        = metricFunc("storageMetadataTotalBytes", metric.GAUGE)
 	metricStorageMetadataUsagePercent = metricFunc("storageMetadataUsagePercent", metric.GAUGE)
+	metricStorageInodesUsed           = metricFunc("storageInodesUsed", metric.GAUGE)
+	metricStorageInodesFree           = metricFunc("storageInodesFree", metric.GAUGE)
+	metricStorageInodesTotal          = metricFunc("storageInodesTotal", metric.GAUGE)
+	metricStorageInodesUsagePercent   = metricFunc("storageInodesUsagePercent", metric.GAUGE)
 )
 
 type entry struct {
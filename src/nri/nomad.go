@@ -0,0 +1,44 @@
+package nri
+
+import (
+	"github.com/newrelic/infra-integrations-sdk/v3/data/attribute"
+)
+
+const (
+	nomadAllocationSampleName = "NomadAllocationSample"
+
+	nomadAllocIDLabel       = "com.hashicorp.nomad.alloc_id"
+	nomadJobNameLabel       = "com.hashicorp.nomad.job_name"
+	nomadTaskGroupNameLabel = "com.hashicorp.nomad.task_group_name"
+	nomadTaskNameLabel      = "com.hashicorp.nomad.task_name"
+	nomadNamespaceLabel     = "com.hashicorp.nomad.namespace"
+
+	attrNomadAllocID   = "nomadAllocId"
+	attrNomadJobName   = "nomadJobName"
+	attrNomadTaskGroup = "nomadTaskGroup"
+	attrNomadNamespace = "nomadNamespace"
+)
+
+// nomadAllocationGroup groups the task containers of Nomad allocations.
+var nomadAllocationGroup = containerGroup{
+	eventType: nomadAllocationSampleName,
+	total:     metricTotalTasks,
+	running:   metricRunningTasks,
+	attributes: func(labels map[string]string) []attribute.Attribute {
+		allocID := labels[nomadAllocIDLabel]
+		if allocID == "" {
+			return nil
+		}
+		attributes := []attribute.Attribute{attribute.Attr(attrNomadAllocID, allocID)}
+		for _, a := range []struct{ label, name string }{
+			{nomadJobNameLabel, attrNomadJobName},
+			{nomadTaskGroupNameLabel, attrNomadTaskGroup},
+			{nomadNamespaceLabel, attrNomadNamespace},
+		} {
+			if value := labels[a.label]; value != "" {
+				attributes = append(attributes, attribute.Attr(a.name, value))
+			}
+		}
+		return attributes
+	},
+}
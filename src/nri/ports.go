@@ -0,0 +1,88 @@
+package nri
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/newrelic/infra-integrations-sdk/v3/data/attribute"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+)
+
+const (
+	containerPortSampleName = "ContainerPortSample"
+	attrContainerPort       = "containerPort"
+	attrProtocol            = "protocol"
+	attrHostIP              = "hostIp"
+	attrHostPort            = "hostPort"
+)
+
+// ports returns the attributes summarizing the ports exposed by the container and the ones published on the host,
+// e.g. exposedPorts: "80/tcp,443/tcp" and publishedPorts: "0.0.0.0:8080->80/tcp".
+func ports(c container.Summary) []entry {
+	exposed := map[string]struct{}{}
+	published := map[string]struct{}{}
+	for _, p := range c.Ports {
+		port := fmt.Sprintf("%d/%s", p.PrivatePort, p.Type)
+		exposed[port] = struct{}{}
+		if p.PublicPort != 0 {
+			published[fmt.Sprintf("%s->%s", hostAddress(p), port)] = struct{}{}
+		}
+	}
+
+	var entries []entry
+	if len(exposed) > 0 {
+		entries = append(entries, metricExposedPorts(joinSorted(exposed)))
+	}
+	if len(published) > 0 {
+		entries = append(entries, metricPublishedPorts(joinSorted(published)))
+	}
+	return entries
+}
+
+// populatePortSamples creates a ContainerPortSample for each of the container ports.
+func populatePortSamples(entity *integration.Entity, c container.Summary) {
+	for _, p := range c.Ports {
+		attributes := []attribute.Attribute{
+			attribute.Attr(attrContainerID, c.ID),
+			attribute.Attr(attrContainerPort, strconv.Itoa(int(p.PrivatePort))),
+			attribute.Attr(attrProtocol, p.Type),
+		}
+		if p.PublicPort != 0 {
+			attributes = append(attributes, attribute.Attr(attrHostPort, strconv.Itoa(int(p.PublicPort))))
+		}
+		if p.IP.IsValid() {
+			attributes = append(attributes, attribute.Attr(attrHostIP, p.IP.String()))
+		}
+		entries := []entry{metricPublished(strconv.FormatBool(p.PublicPort != 0))}
+		// the host IP of published ports is unknown for Fargate containers, so they can't be told publicly bound
+		if p.PublicPort == 0 || p.IP.IsValid() {
+			entries = append(entries, metricPubliclyBound(strconv.FormatBool(p.PublicPort != 0 && p.IP.IsUnspecified())))
+		}
+		ms := entity.NewMetricSet(containerPortSampleName, attributes...)
+		populate(ms, entries)
+	}
+}
+
+// hostAddress returns the host IP and port of a published port, in the format used by the docker CLI.
+func hostAddress(p container.PortSummary) string {
+	port := strconv.Itoa(int(p.PublicPort))
+	if !p.IP.IsValid() {
+		return port
+	}
+	if p.IP.Is6() {
+		return "[" + p.IP.String() + "]:" + port
+	}
+	return p.IP.String() + ":" + port
+}
+
+func joinSorted(set map[string]struct{}) string {
+	values := make([]string, 0, len(set))
+	for v := range set {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	return strings.Join(values, ",")
+}
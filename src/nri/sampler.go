@@ -3,12 +3,14 @@ package nri
 import (
 	"context"
 	"errors"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/moby/moby/api/types/container"
 	"github.com/moby/moby/api/types/system"
 	"github.com/newrelic/infra-integrations-sdk/v3/data/attribute"
+	"github.com/newrelic/infra-integrations-sdk/v3/data/inventory"
 	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
 	"github.com/newrelic/infra-integrations-sdk/v3/integration"
 	"github.com/newrelic/infra-integrations-sdk/v3/log"
@@ -17,6 +19,7 @@ import (
 	"github.com/newrelic/nri-docker/src/biz"
 	"github.com/newrelic/nri-docker/src/config"
 	"github.com/newrelic/nri-docker/src/raw"
+	"github.com/newrelic/nri-docker/src/raw/aws"
 )
 
 const (
@@ -30,14 +33,21 @@ const (
 // ContainerSampler invokes the metrics sampling and processing for all the existing containers, and populates the
 // integration execution information with the exported metrics
 type ContainerSampler struct {
-	metrics biz.Processer
-	store   persist.Storer
-	docker  raw.DockerClient
-	config  config.ArgumentList
+	metrics  biz.Processer
+	store    persist.Storer
+	docker   raw.DockerClient
+	swarm    raw.SwarmClient
+	versions raw.VersionedClient
+	ecs      aws.TaskMetadataClient
+	config   config.ArgumentList
+	labels   labelMapper
+	redactor *biz.Redactor
+	daemon   Daemon
 }
 
-// NewSampler returns a ContainerSampler instance.
-func NewSampler(fetcher raw.Fetcher, docker raw.DockerClient, config config.ArgumentList) (*ContainerSampler, error) {
+// NewSampler returns a ContainerSampler instance. The configFile holds the settings of the config_file argument,
+// and daemon identifies the sampled daemon when several are monitored.
+func NewSampler(fetcher raw.Fetcher, docker raw.DockerClient, config config.ArgumentList, configFile config.File, daemon Daemon) (*ContainerSampler, error) {
 	exitedContainerTTL, err := time.ParseDuration(config.ExitedContainersTTL)
 	if err != nil {
 		return nil, err
@@ -45,19 +55,61 @@ func NewSampler(fetcher raw.Fetcher, docker raw.DockerClient, config config.Argu
 
 	// SDK Storer to keep metric values between executions (e.g. for rates and deltas)
 	store, err := persist.NewFileStore(
-		persist.TmpPath(config.TempDir, "container_cpus"),
+		persist.TmpPath(config.TempDir, daemon.storeName()),
 		log.NewStdErr(true),
 		config.CacheTTL)
 	if err != nil {
 		return nil, err
 	}
 
-	return &ContainerSampler{
-		metrics: biz.NewProcessor(store, fetcher, docker, exitedContainerTTL),
-		docker:  docker,
-		store:   store,
-		config:  config,
-	}, nil
+	envValuesPolicy, err := biz.ParseEnvValuesPolicy(config.InventoryEnvValues)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, redactor, err := fromConfigFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	processor := biz.NewProcessor(store, fetcher, docker, exitedContainerTTL)
+	processor.WithHostRoot(config.HostRoot)
+	processor.WithEnvValuesPolicy(envValuesPolicy)
+	processor.WithRedactor(redactor)
+
+	sampler := &ContainerSampler{
+		metrics:  processor,
+		docker:   docker,
+		store:    store,
+		config:   config,
+		labels:   labels,
+		redactor: redactor,
+		daemon:   daemon,
+	}
+	// Swarm metadata is only available if the client is connected to the docker API.
+	if swarmClient, ok := docker.(raw.SwarmClient); ok {
+		sampler.swarm = swarmClient
+	}
+	// Task metadata is only available if the client is connected to the ECS metadata endpoint.
+	if ecsClient, ok := docker.(aws.TaskMetadataClient); ok {
+		sampler.ecs = ecsClient
+	}
+	// The API version is only known if the client is connected to the docker API.
+	if versionedClient, ok := docker.(raw.VersionedClient); ok {
+		sampler.versions = versionedClient
+	}
+	return sampler, nil
+}
+
+// fromConfigFile returns the labelMapper and the Redactor defined in the configuration file.
+func fromConfigFile(file config.File) (labelMapper, *biz.Redactor, error) {
+	redactor, err := biz.NewRedactor(file.Redaction.Patterns)
+	if err != nil {
+		return labelMapper{}, nil, err
+	}
+
+	labels, err := newLabelMapper(file.Labels, redactor)
+	return labels, redactor, err
 }
 
 // SampleAll populates the integration of the argument with metrics and labels from all the containers in the system
@@ -79,15 +131,29 @@ func (cs *ContainerSampler) SampleAll(ctx context.Context, i *integration.Integr
 
 	var storageEntry []entry
 	if !cs.config.DisableStorageMetrics {
-		storageStats, err := biz.ParseDeviceMapperStats(cgroupInfo)
-		if err != nil {
+		storageStats, err := biz.ParseStorageDriverStats(cgroupInfo, cs.config.HostRoot)
+		switch {
+		case errors.Is(err, biz.ErrUnsupportedStorageDriver):
+			log.Debug("skipping Storage Driver stats: %s", err.Error())
+		case err != nil:
 			log.Warn("computing Storage Driver stats: %s", err.Error())
 		}
 		storageEntry = getStorageEntry(storageStats)
 	}
 
+	groups := newGroupAggregators(composeServiceGroup, nomadAllocationGroup)
+	swarmMeta := fetchSwarmMetadata(ctx, cs.swarm, cgroupInfo)
+	task := fetchECSTask(ctx, cs.ecs)
+
 	for _, container := range containers {
-		metrics, err := cs.metrics.Process(container.ID)
+		redactions := cs.redactor.Count()
+
+		if cs.config.SkipKubernetesSandboxes && raw.IsKubernetesSandbox(container.Labels) {
+			log.Debug("Skipped Kubernetes sandbox container: %s.", container.ID)
+			continue
+		}
+
+		metrics, err := cs.metrics.Process(ctx, container.ID)
 		if err != nil {
 			switch {
 			case errors.Is(err, biz.ErrExitedContainerExpired):
@@ -101,7 +167,7 @@ func (cs *ContainerSampler) SampleAll(ctx context.Context, i *integration.Integr
 		}
 
 		// Creating entity and populating metrics
-		entity, err := i.Entity(container.ID, "docker")
+		entity, err := i.Entity(container.ID, "docker", cs.daemon.idAttributes()...)
 		if err != nil {
 			return err
 		}
@@ -113,29 +179,42 @@ func (cs *ContainerSampler) SampleAll(ctx context.Context, i *integration.Integr
 			shortContainerID = container.ID
 		}
 
-		ms := entity.NewMetricSet(containerSampleName,
+		ms := entity.NewMetricSet(containerSampleName, append([]attribute.Attribute{
 			attribute.Attr(attrContainerID, container.ID),
 			attribute.Attr(attrShortContainerID, shortContainerID),
-		)
+		}, cs.daemon.attributes()...)...)
 
 		// populating metrics that are common to running and stopped containers
-		populate(ms, attributes(container))
-		populate(ms, labels(container))
+		populate(ms, attributes(container, cs.redactor))
+		populate(ms, ports(container))
+		populate(ms, swarmMeta.attributes(container.Labels))
+		populate(ms, kubernetes(container.Labels))
+		populate(ms, cs.labels.labels(container.Labels))
 		populate(ms, storageEntry)
+		populate(ms, cs.security(metrics.Security))
+		populate(ms, exit(metrics.Exit))
+
+		populatePortSamples(entity, container)
+
+		if !cs.config.DisableInventory && cs.config.HasInventory() {
+			populateInventory(entity, metrics.Inventory)
+		}
 
-		// TODO: this *needs* to be refactored into the call to ContainerList, because different
-		// systems might represent running containers in a slightly different way. This can be tricky
-		// because for Docker containers we're relying on the capabilities of the official Docker client.
-		// Possibly wrapping the Docker client with another type is a good solution.
-		// i.e. Docker uses `state = running` and ECS uses `status = RUNNING`.
-		// If State is empty, we check by status up.
-		if strings.ToLower(string(container.State)) != "running" &&
-			strings.ToLower(container.Status) != "running" &&
-			!strings.HasPrefix(strings.ToLower(container.Status), "up") {
+		if cs.redactor != nil {
+			populate(ms, []entry{metricRedactedValues(cs.redactor.Count() - redactions)})
+		}
+
+		running := isRunning(container)
+		for _, g := range groups {
+			g.add(container.Labels, running, &metrics)
+		}
+		if !running {
 			log.Debug("Skipped not running container: %s.", container.ID)
 			continue
 		}
 
+		task.add(&metrics)
+
 		// populating metrics that only apply to running containers
 		populate(ms, misc(&metrics))
 		populate(ms, cpu(&metrics.CPU))
@@ -143,10 +222,31 @@ func (cs *ContainerSampler) SampleAll(ctx context.Context, i *integration.Integr
 		populate(ms, pids(&metrics.Pids))
 		populate(ms, blkio(&metrics.BlkIO))
 		populate(ms, cs.networkMetrics(&metrics.Network))
+		populate(ms, logs(&metrics.Logs))
 	}
+
+	for _, g := range groups {
+		g.populate(i, cs.daemon.attributes()...)
+	}
+	swarmMeta.populateServiceSamples(i, cs.daemon.attributes()...)
+	task.populate(i, cs.daemon.attributes()...)
+	cs.daemon.populateSample(i, cs.versions, cgroupInfo)
 	return nil
 }
 
+// isRunning returns true if the container is running.
+// TODO: this *needs* to be refactored into the call to ContainerList, because different
+// systems might represent running containers in a slightly different way. This can be tricky
+// because for Docker containers we're relying on the capabilities of the official Docker client.
+// Possibly wrapping the Docker client with another type is a good solution.
+// i.e. Docker uses `state = running` and ECS uses `status = RUNNING`.
+// If State is empty, we check by status up.
+func isRunning(container container.Summary) bool {
+	return strings.ToLower(string(container.State)) == "running" ||
+		strings.ToLower(container.Status) == "running" ||
+		strings.HasPrefix(strings.ToLower(container.Status), "up")
+}
+
 func populate(ms *metric.Set, metrics []entry) {
 	for _, m := range metrics {
 		if err := ms.SetMetric(m.Name, m.Value, m.Type); err != nil {
@@ -155,7 +255,17 @@ func populate(ms *metric.Set, metrics []entry) {
 	}
 }
 
-func attributes(container container.Summary) []entry {
+func populateInventory(entity *integration.Entity, items inventory.Items) {
+	for key, item := range items {
+		for field, value := range item {
+			if err := entity.SetInventoryItem(key, field, value); err != nil {
+				log.Warn("Unexpected error setting inventory item %s: %v", key, err)
+			}
+		}
+	}
+}
+
+func attributes(container container.Summary, redactor *biz.Redactor) []entry {
 	var cname string
 	if len(container.Names) > 0 {
 		cname = container.Names[0]
@@ -164,7 +274,7 @@ func attributes(container container.Summary) []entry {
 		}
 	}
 	entries := []entry{
-		metricCommandLine(container.Command),
+		metricCommandLine(redactor.Redact(container.Command)),
 		metricContainerName(cname),
 		metricContainerImage(container.ImageID),
 		metricContainerImageName(container.Image),
@@ -201,35 +311,57 @@ var labelRename = map[string]string{
 	"com.amazonaws.ecs.task-definition-family":  "ecsTaskDefinitionFamily",
 	"com.amazonaws.ecs.task-definition-version": "ecsTaskDefinitionVersion",
 
-	// com.newrelic.nri-docker.* labels are created by aws.processFargateLabels containing fargate info
-	"com.newrelic.nri-docker.launch-type": "ecsLaunchType",
-	"com.newrelic.nri-docker.cluster-arn": "ecsClusterArn",
-	"com.newrelic.nri-docker.aws-region":  "awsRegion",
-}
+	// Docker Compose labels
+	"com.docker.compose.project":          "composeProject",
+	"com.docker.compose.service":          "composeService",
+	"com.docker.compose.container-number": "composeReplica",
 
-func labels(container container.Summary) []entry {
-	metrics := make([]entry, 0, len(container.Labels))
-	for key, val := range container.Labels {
-		if newName, ok := labelRename[key]; ok {
-			metrics = append(metrics, entry{
-				Name:  newName,
-				Value: val,
-				Type:  metric.ATTRIBUTE,
-			})
-		}
+	// Docker Swarm labels
+	"com.docker.swarm.service.id":   "swarmServiceId",
+	"com.docker.swarm.service.name": "swarmServiceName",
+	"com.docker.swarm.task.id":      "swarmTaskId",
+	"com.docker.swarm.task.name":    "swarmTaskName",
+	"com.docker.swarm.node.id":      "swarmNodeId",
 
-		metrics = append(metrics, entry{
-			Name:  labelPrefix + key,
-			Value: val,
-			Type:  metric.ATTRIBUTE,
-		})
-	}
+	// HashiCorp Nomad labels
+	nomadAllocIDLabel:       attrNomadAllocID,
+	nomadJobNameLabel:       attrNomadJobName,
+	nomadTaskGroupNameLabel: attrNomadTaskGroup,
+	nomadTaskNameLabel:      "nomadTaskName",
+	nomadNamespaceLabel:     attrNomadNamespace,
+
+	// Kubernetes labels set by dockershim/cri-dockerd
+	raw.KubernetesPodNameLabel:       "podName",
+	raw.KubernetesPodNamespaceLabel:  "namespace",
+	raw.KubernetesContainerNameLabel: "k8sContainerName",
+
+	// com.newrelic.nri-docker.* labels are created by aws.fargateLabels containing fargate info
+	"com.newrelic.nri-docker.launch-type":    "ecsLaunchType",
+	"com.newrelic.nri-docker.cluster-arn":    "ecsClusterArn",
+	"com.newrelic.nri-docker.aws-region":     "awsRegion",
+	"com.newrelic.nri-docker.desired-status": "desiredStatus",
+	"com.newrelic.nri-docker.container-arn":  "containerArn",
+	"com.newrelic.nri-docker.account-id":     "awsAccountId",
+	"com.newrelic.nri-docker.log-group":      "awsLogGroup",
+	"com.newrelic.nri-docker.log-stream":     "awsLogStream",
 
-	return metrics
+	// ECS on EC2 details, created by aws.ECSInspector along with the launch type, cluster ARN and region
+	"com.newrelic.nri-docker.service-name":           "ecsServiceName",
+	"com.newrelic.nri-docker.container-instance-arn": "containerInstanceArn",
+	"com.newrelic.nri-docker.availability-zone":      "awsAvailabilityZone",
+
+	// network details, only reported by the task metadata endpoint v4 for awsvpc tasks
+	"com.newrelic.nri-docker.network-mode":           "networkMode",
+	"com.newrelic.nri-docker.ipv4-address":           "ipv4Address",
+	"com.newrelic.nri-docker.ipv6-address":           "ipv6Address",
+	"com.newrelic.nri-docker.ipv4-subnet-cidr-block": "ipv4SubnetCidrBlock",
+	"com.newrelic.nri-docker.ipv6-subnet-cidr-block": "ipv6SubnetCidrBlock",
+	"com.newrelic.nri-docker.mac-address":            "macAddress",
+	"com.newrelic.nri-docker.private-dns-name":       "privateDnsName",
 }
 
 func (cs *ContainerSampler) networkMetrics(net *biz.Network) []entry {
-	return []entry{
+	entries := []entry{
 		metricRxBytes(net.RxBytes),
 		metricRxErrors(net.RxErrors),
 		metricRxDropped(net.RxDropped),
@@ -238,35 +370,126 @@ func (cs *ContainerSampler) networkMetrics(net *biz.Network) []entry {
 		metricTxErrors(net.TxErrors),
 		metricTxDropped(net.TxDropped),
 		metricTxPackets(net.TxPackets),
-		metricRxBytesPerSecond(net.RxBytes),
 		metricRxErrorsPerSecond(net.RxErrors),
 		metricRxDroppedPerSecond(net.RxDropped),
 		metricRxPacketsPerSecond(net.RxPackets),
-		metricTxBytesPerSecond(net.TxBytes),
 		metricTxErrorsPerSecond(net.TxErrors),
 		metricTxDroppedPerSecond(net.TxDropped),
 		metricTxPacketsPerSecond(net.TxPackets),
 	}
+	// the bytes rates are reported by the source or computed from the previous sampling, if any
+	if net.RxBytesPerSecond != nil {
+		entries = append(entries, metricRxBytesPerSecond(*net.RxBytesPerSecond))
+	}
+	if net.TxBytesPerSecond != nil {
+		entries = append(entries, metricTxBytesPerSecond(*net.TxBytesPerSecond))
+	}
+	return entries
 }
 
-func misc(m *biz.Sample) []entry {
+// exit returns how an exited container finished, or nothing for the running ones.
+func exit(e *biz.Exit) []entry {
+	if e == nil {
+		return []entry{}
+	}
 	return []entry{
+		metricExitCode(e.Code),
+		metricFinishedAt(e.FinishedAt),
+		metricStopReason(e.Reason),
+	}
+}
+
+func misc(m *biz.Sample) []entry {
+	entries := []entry{
 		metricRestartCount(m.RestartCount),
 	}
+	if m.StartLatencySeconds != nil {
+		entries = append(entries, metricStartLatencySeconds(*m.StartLatencySeconds))
+	}
+	return entries
 }
 
-func getStorageEntry(m *biz.DeviceMapperStats) []entry {
+func (cs *ContainerSampler) security(s *biz.Security) []entry {
+	if s == nil {
+		return []entry{}
+	}
+	entries := []entry{
+		metricPrivileged(strconv.FormatBool(s.Privileged)),
+		metricReadonlyRootfs(strconv.FormatBool(s.ReadonlyRootfs)),
+		metricRunsAsRoot(strconv.FormatBool(s.RunsAsRoot)),
+		metricHostPID(strconv.FormatBool(s.HostPID)),
+		metricHostIPC(strconv.FormatBool(s.HostIPC)),
+		metricHostNetwork(strconv.FormatBool(s.HostNetwork)),
+		metricSeccompProfile(s.SeccompProfile),
+	}
+	optional := []entry{
+		metricCapAdd(strings.Join(s.CapAdd, ",")),
+		metricCapDrop(strings.Join(s.CapDrop, ",")),
+		metricAppArmorProfile(s.AppArmorProfile),
+		metricSELinuxLabel(s.SELinuxLabel),
+		metricUser(s.User),
+		metricUsernsMode(s.UsernsMode),
+		metricSensitiveHostMounts(strings.Join(s.SensitiveMounts, ",")),
+	}
+	for _, e := range optional {
+		if !isAttributeValueEmpty(e) {
+			entries = append(entries, e)
+		}
+	}
+	if cs.config.SecurityRiskScore {
+		entries = append(entries, metricSecurityRiskScore(s.RiskScore))
+	}
+	return entries
+}
+
+func logs(l *biz.Logs) []entry {
+	if l.Driver == "" {
+		return []entry{}
+	}
+	entries := []entry{
+		metricLogDriver(l.Driver),
+	}
+	if l.SizeBytes != nil {
+		entries = append(entries, metricLogFilesSizeBytes(*l.SizeBytes))
+	}
+	if l.BytesWrittenPerSecond != nil {
+		entries = append(entries, metricLogBytesWrittenPerSecond(*l.BytesWrittenPerSecond))
+	}
+	if l.MaxSizeBytes > 0 {
+		entries = append(entries, metricLogMaxSizeBytes(l.MaxSizeBytes))
+	}
+	if l.MaxFiles > 0 {
+		entries = append(entries, metricLogMaxFiles(l.MaxFiles))
+	}
+	return entries
+}
+
+func getStorageEntry(m *biz.StorageDriverStats) []entry {
 	if m == nil {
 		return []entry{}
 	}
-	return []entry{
+	entries := []entry{
+		metricStorageDriver(m.Driver),
 		metricStorageDataUsed(m.DataUsed),
 		metricStorageDataAvailable(m.DataAvailable),
 		metricStorageDataTotal(m.DataTotal),
 		metricStorageDataUsagePercent(m.DataUsagePercent),
-		metricStorageMetadataUsed(m.MetadataUsed),
-		metricStorageMetadataAvailable(m.MetadataAvailable),
-		metricStorageMetadataTotal(m.MetadataTotal),
-		metricStorageMetadataUsagePercent(m.MetadataUsagePercent),
 	}
+	if m.HasMetadata {
+		entries = append(entries,
+			metricStorageMetadataUsed(m.MetadataUsed),
+			metricStorageMetadataAvailable(m.MetadataAvailable),
+			metricStorageMetadataTotal(m.MetadataTotal),
+			metricStorageMetadataUsagePercent(m.MetadataUsagePercent),
+		)
+	}
+	if m.Inodes != nil {
+		entries = append(entries,
+			metricStorageInodesUsed(m.Inodes.Used),
+			metricStorageInodesFree(m.Inodes.Free),
+			metricStorageInodesTotal(m.Inodes.Total),
+			metricStorageInodesUsagePercent(m.Inodes.UsagePercent),
+		)
+	}
+	return entries
 }
@@ -2,11 +2,16 @@ package nri
 
 import (
 	"context"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 	"time"
 
 	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -16,8 +21,10 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"github.com/newrelic/nri-docker/src/biz"
+	"github.com/newrelic/nri-docker/src/config"
 	"github.com/newrelic/nri-docker/src/constants"
 	"github.com/newrelic/nri-docker/src/raw"
+	"github.com/newrelic/nri-docker/src/raw/aws"
 )
 
 // mocker is a Docker mock
@@ -67,11 +74,47 @@ type mockFetcher struct {
 	mock.Mock
 }
 
-func (m *mockFetcher) Fetch(json container.InspectResponse) (raw.Metrics, error) {
+func (m *mockFetcher) Fetch(_ context.Context, json container.InspectResponse) (raw.Metrics, error) {
 	args := m.Called(json)
 	return args.Get(0).(raw.Metrics), nil
 }
 
+// newTestSampler returns a sampler of the given containers, whose inspect responses are taken from inspects or
+// are the ones of running containers otherwise, and whose stats are the given metrics.
+func newTestSampler(t *testing.T, containers []container.Summary, inspects map[string]container.InspectResponse, stats raw.Metrics) *ContainerSampler {
+	t.Helper()
+
+	docker := &mocker{}
+	docker.On("ContainerList", mock.Anything, mock.Anything).Return(containers, nil)
+	for _, c := range containers {
+		inspect, ok := inspects[c.ID]
+		if !ok {
+			inspect = container.InspectResponse{ID: c.ID, State: &container.State{Status: "running"}}
+		}
+		docker.On("ContainerInspect", mock.Anything, c.ID).Return(inspect, nil)
+	}
+
+	mStore := storerMock()
+	fetcher := &mockFetcher{}
+	fetcher.On("Fetch", mock.Anything).Return(stats, nil)
+
+	return &ContainerSampler{
+		metrics: biz.NewProcessor(mStore, fetcher, docker, 30*time.Minute),
+		docker:  docker,
+		store:   mStore,
+	}
+}
+
+// sampleAll samples the containers of the sampler into a new integration.
+func sampleAll(t *testing.T, sampler *ContainerSampler) *integration.Integration {
+	t.Helper()
+
+	i, err := integration.New("test", "test-version")
+	require.NoError(t, err)
+	require.NoError(t, sampler.SampleAll(context.Background(), i, system.Info{}))
+	return i
+}
+
 func TestECSLabelRename(t *testing.T) {
 	var (
 		givenLabels = map[string]string{
@@ -172,6 +215,13 @@ func TestSampleAll(t *testing.T) {
 			Status: "running",
 		},
 		RestartCount: 1,
+		HostConfig: &container.HostConfig{
+			RestartPolicy: container.RestartPolicy{Name: "always"},
+			LogConfig: container.LogConfig{
+				Type:   "journald",
+				Config: map[string]string{"max-size": "1k"},
+			},
+		},
 	}, nil)
 
 	mStore := storerMock()
@@ -206,6 +256,23 @@ func TestSampleAll(t *testing.T) {
 	assert.Equal(t, float64(1), metrics["restartCount"])
 	assert.NotContains(t, metrics, "state", "container attributes are not populated with empty values")
 
+	// Inventory
+	item, ok := i.Entities[0].Inventory.Item("restartPolicy")
+	require.True(t, ok)
+	assert.Equal(t, "always", item["name"])
+
+	// Security
+	assert.Equal(t, "false", metrics["privileged"])
+	assert.Equal(t, "true", metrics["runsAsRoot"])
+	assert.Equal(t, "default", metrics["seccompProfile"])
+	assert.NotContains(t, metrics, "capAdd")
+	assert.NotContains(t, metrics, "securityRiskScore", "risk score is only reported if enabled")
+
+	// Logs
+	assert.Equal(t, "journald", metrics["logDriver"])
+	assert.Equal(t, float64(1024), metrics["logMaxSizeBytes"])
+	assert.NotContains(t, metrics, "logFilesSizeBytes", "log files size is only reported for drivers storing logs on disk")
+
 	// Labels
 	assert.Equal(t, metrics["label.noValue"], "", "empty label value should be preserved")
 	assert.Equal(t, metrics["label.value"], "foo")
@@ -421,3 +488,479 @@ func allMetrics() raw.Metrics {
 		},
 	}
 }
+
+func TestSampleAllPorts(t *testing.T) {
+	c := testingContainer
+	c.Ports = []container.PortSummary{
+		{IP: netip.MustParseAddr("0.0.0.0"), PrivatePort: 80, PublicPort: 8080, Type: "tcp"},
+		{IP: netip.MustParseAddr("::"), PrivatePort: 80, PublicPort: 8080, Type: "tcp"},
+		{IP: netip.MustParseAddr("127.0.0.1"), PrivatePort: 9090, PublicPort: 9090, Type: "tcp"},
+		{PrivatePort: 53, Type: "udp"},
+		// awsvpc ports of Fargate containers have no host IP
+		{PrivatePort: 8443, PublicPort: 8443, Type: "tcp"},
+	}
+	i := sampleAll(t, newTestSampler(t, []container.Summary{c}, nil, allMetrics()))
+	require.Len(t, i.Entities, 1)
+	require.Len(t, i.Entities[0].Metrics, 6)
+
+	metrics := i.Entities[0].Metrics[0].Metrics
+	assert.Equal(t, "53/udp,80/tcp,8443/tcp,9090/tcp", metrics["exposedPorts"])
+	assert.Equal(t, "0.0.0.0:8080->80/tcp,127.0.0.1:9090->9090/tcp,8443->8443/tcp,[::]:8080->80/tcp", metrics["publishedPorts"])
+
+	expected := []map[string]interface{}{
+		{"containerPort": "80", "protocol": "tcp", "hostIp": "0.0.0.0", "hostPort": "8080", "published": "true", "publiclyBound": "true"},
+		{"containerPort": "80", "protocol": "tcp", "hostIp": "::", "hostPort": "8080", "published": "true", "publiclyBound": "true"},
+		{"containerPort": "9090", "protocol": "tcp", "hostIp": "127.0.0.1", "hostPort": "9090", "published": "true", "publiclyBound": "false"},
+		{"containerPort": "53", "protocol": "udp", "published": "false", "publiclyBound": "false"},
+		{"containerPort": "8443", "protocol": "tcp", "hostPort": "8443", "published": "true"},
+	}
+	for idx, want := range expected {
+		portSample := i.Entities[0].Metrics[idx+1].Metrics
+		assert.Equal(t, "ContainerPortSample", portSample["event_type"])
+		assert.Equal(t, containerID, portSample["containerId"])
+		for k, v := range want {
+			assert.Equal(t, v, portSample[k], "port sample %d attribute %s", idx, k)
+		}
+		if _, ok := want["hostPort"]; !ok {
+			assert.NotContains(t, portSample, "hostPort")
+		}
+		if _, ok := want["publiclyBound"]; !ok {
+			assert.NotContains(t, portSample, "publiclyBound")
+		}
+	}
+}
+
+func TestComposeServiceSample(t *testing.T) {
+	composeContainer := func(id, service, number, state string, oneoff bool) container.Summary {
+		labels := map[string]string{
+			"com.docker.compose.project":          "shop",
+			"com.docker.compose.service":          service,
+			"com.docker.compose.container-number": number,
+		}
+		if oneoff {
+			labels["com.docker.compose.oneoff"] = "True"
+		}
+		return container.Summary{ID: id, Names: []string{id}, State: container.ContainerState(state), Labels: labels}
+	}
+
+	i := sampleAll(t, newTestSampler(t, []container.Summary{
+		composeContainer("web-1", "web", "1", "running", false),
+		composeContainer("web-2", "web", "2", "running", false),
+		composeContainer("web-3", "web", "3", "exited", false),
+		composeContainer("web-run", "web", "1", "running", true),
+		composeContainer("db-1", "db", "1", "running", false),
+	}, map[string]container.InspectResponse{
+		"web-3": {ID: "web-3", State: &container.State{Status: "exited", FinishedAt: time.Now().Format(time.RFC3339Nano)}},
+	}, allMetrics()))
+
+	// compose attributes are promoted from labels
+	web1, err := i.Entity("web-1", "docker")
+	require.NoError(t, err)
+	assert.Equal(t, "shop", web1.Metrics[0].Metrics["composeProject"])
+	assert.Equal(t, "web", web1.Metrics[0].Metrics["composeService"])
+	assert.Equal(t, "1", web1.Metrics[0].Metrics["composeReplica"])
+
+	samples := i.LocalEntity().Metrics
+	require.Len(t, samples, 2)
+
+	db := samples[0].Metrics
+	assert.Equal(t, "ComposeServiceSample", db["event_type"])
+	assert.Equal(t, "db", db["composeService"])
+	assert.Equal(t, float64(1), db["totalReplicas"])
+	assert.Equal(t, float64(1), db["runningReplicas"])
+
+	web := samples[1].Metrics
+	assert.Equal(t, "shop", web["composeProject"])
+	assert.Equal(t, "web", web["composeService"])
+	assert.Equal(t, float64(3), web["totalReplicas"], "one-off containers are not replicas")
+	assert.Equal(t, float64(2), web["runningReplicas"])
+	assert.Equal(t, float64(2*nonZeroUint), web["memoryUsageBytes"])
+	assert.Equal(t, float64(2*nonZero), web["networkRxBytes"])
+	assert.Equal(t, 2*db["cpuLimitCores"].(float64), web["cpuLimitCores"])
+	assert.Contains(t, web, "cpuUsedCoresPercent")
+	assert.NotContains(t, web, "cpuPercent", "percentages of each replica can't be added")
+	assert.NotContains(t, web, "networkRxBytesPerSecond", "rates of each replica can't be derived from the summed counters")
+}
+
+func TestKubernetesContainers(t *testing.T) {
+	podContainer := func(id, containerType string) container.Summary {
+		return container.Summary{ID: id, Names: []string{id}, State: container.StateRunning, Labels: map[string]string{
+			"io.kubernetes.pod.name":       "nginx-7c5ddbdf54-x2v9k",
+			"io.kubernetes.pod.namespace":  "default",
+			"io.kubernetes.container.name": "nginx",
+			"io.kubernetes.docker.type":    containerType,
+		}}
+	}
+
+	for _, skip := range []bool{false, true} {
+		t.Run(fmt.Sprintf("skip sandboxes %v", skip), func(t *testing.T) {
+			sampler := newTestSampler(t, []container.Summary{
+				podContainer("nginx", "container"),
+				podContainer("pause", "podsandbox"),
+			}, nil, allMetrics())
+			sampler.config = config.ArgumentList{SkipKubernetesSandboxes: skip}
+
+			i := sampleAll(t, sampler)
+
+			if skip {
+				require.Len(t, i.Entities, 1)
+			} else {
+				require.Len(t, i.Entities, 2)
+				assert.Equal(t, "true", i.Entities[1].Metrics[0].Metrics["k8sSandbox"])
+			}
+
+			metrics := i.Entities[0].Metrics[0].Metrics
+			assert.Equal(t, "nginx-7c5ddbdf54-x2v9k", metrics["podName"])
+			assert.Equal(t, "default", metrics["namespace"])
+			assert.Equal(t, "nginx", metrics["k8sContainerName"])
+			assert.Equal(t, "false", metrics["k8sSandbox"])
+		})
+	}
+}
+
+func TestNomadAllocationSample(t *testing.T) {
+	nomadTask := func(id, allocID, task, state string) container.Summary {
+		return container.Summary{ID: id, Names: []string{id}, State: container.ContainerState(state), Labels: map[string]string{
+			"com.hashicorp.nomad.alloc_id":        allocID,
+			"com.hashicorp.nomad.job_name":        "api",
+			"com.hashicorp.nomad.task_group_name": "web",
+			"com.hashicorp.nomad.task_name":       task,
+			"com.hashicorp.nomad.namespace":       "default",
+		}}
+	}
+
+	i := sampleAll(t, newTestSampler(t, []container.Summary{
+		nomadTask("server-a", "alloc-a", "server", "running"),
+		nomadTask("sidecar-a", "alloc-a", "envoy", "running"),
+		nomadTask("server-b", "alloc-b", "server", "running"),
+	}, nil, allMetrics()))
+
+	sidecar, err := i.Entity("sidecar-a", "docker")
+	require.NoError(t, err)
+	assert.Equal(t, "alloc-a", sidecar.Metrics[0].Metrics["nomadAllocId"])
+	assert.Equal(t, "api", sidecar.Metrics[0].Metrics["nomadJobName"])
+	assert.Equal(t, "web", sidecar.Metrics[0].Metrics["nomadTaskGroup"])
+	assert.Equal(t, "envoy", sidecar.Metrics[0].Metrics["nomadTaskName"])
+	assert.Equal(t, "default", sidecar.Metrics[0].Metrics["nomadNamespace"])
+
+	samples := i.LocalEntity().Metrics
+	require.Len(t, samples, 2)
+
+	allocA := samples[0].Metrics
+	assert.Equal(t, "NomadAllocationSample", allocA["event_type"])
+	assert.Equal(t, "alloc-a", allocA["nomadAllocId"])
+	assert.Equal(t, "api", allocA["nomadJobName"])
+	assert.Equal(t, "web", allocA["nomadTaskGroup"])
+	assert.Equal(t, "default", allocA["nomadNamespace"])
+	assert.Equal(t, float64(2), allocA["totalTasks"])
+	assert.Equal(t, float64(2), allocA["runningTasks"])
+	assert.NotContains(t, allocA, "runningReplicas")
+	assert.Equal(t, float64(2*nonZeroUint), allocA["memoryUsageBytes"])
+
+	assert.Equal(t, "alloc-b", samples[1].Metrics["nomadAllocId"])
+	assert.Equal(t, float64(1), samples[1].Metrics["runningTasks"])
+}
+
+func TestNomadAllocationSampleTasksChange(t *testing.T) {
+	nomadTask := func(id, task string) container.Summary {
+		return container.Summary{ID: id, Names: []string{id}, State: container.StateRunning, Labels: map[string]string{
+			"com.hashicorp.nomad.alloc_id":        "alloc-a",
+			"com.hashicorp.nomad.job_name":        "api",
+			"com.hashicorp.nomad.task_group_name": "web",
+			"com.hashicorp.nomad.task_name":       task,
+		}}
+	}
+	server, sidecar := nomadTask("server-a", "server"), nomadTask("sidecar-a", "envoy")
+
+	// the sidecar leaves the allocation between both samples
+	first := sampleAll(t, newTestSampler(t, []container.Summary{server, sidecar}, nil, allMetrics()))
+	second := sampleAll(t, newTestSampler(t, []container.Summary{server}, nil, allMetrics()))
+
+	require.Len(t, first.LocalEntity().Metrics, 1)
+	assert.Equal(t, float64(2), first.LocalEntity().Metrics[0].Metrics["runningTasks"])
+	assert.Equal(t, float64(2*nonZero), first.LocalEntity().Metrics[0].Metrics["networkRxBytes"])
+
+	require.Len(t, second.LocalEntity().Metrics, 1)
+	alloc := second.LocalEntity().Metrics[0].Metrics
+	assert.Equal(t, float64(1), alloc["totalTasks"])
+	assert.Equal(t, float64(1), alloc["runningTasks"])
+	assert.Equal(t, float64(nonZero), alloc["networkRxBytes"])
+	assert.Equal(t, float64(nonZeroUint), alloc["memoryUsageBytes"])
+	assert.NotContains(t, alloc, "networkRxBytesPerSecond", "the summed counters drop when a task leaves the allocation")
+	assert.NotContains(t, alloc, "networkTxBytesPerSecond")
+}
+
+func TestSampleAllRedaction(t *testing.T) {
+	sampler := newTestSampler(t, []container.Summary{{
+		ID:      "app",
+		Names:   []string{"app"},
+		Command: "app --db-password=hunter2 --port 8080",
+		State:   container.StateRunning,
+		Labels: map[string]string{
+			"com.acme.api-token": "abc123",
+			"com.acme.team":      "payments",
+		},
+	}}, nil, allMetrics())
+
+	var err error
+	sampler.labels, sampler.redactor, err = fromConfigFile(config.File{})
+	require.NoError(t, err)
+
+	i := sampleAll(t, sampler)
+
+	metrics := i.Entities[0].Metrics[0].Metrics
+	assert.Equal(t, "app --db-password=(redacted) --port 8080", metrics["commandLine"])
+	assert.Equal(t, "(redacted)", metrics["label.com.acme.api-token"])
+	assert.Equal(t, "payments", metrics["label.com.acme.team"])
+	assert.Equal(t, float64(2), metrics["redactedValues"])
+}
+
+func TestSampleAllMultipleDaemons(t *testing.T) {
+	i, err := integration.New("test", "test-version")
+	require.NoError(t, err)
+
+	daemons := []Daemon{
+		{Host: "unix:///var/run/docker.sock", ID: "rootful"},
+		{Host: "unix:///run/user/1000/docker.sock", ID: "rootless"},
+	}
+	for _, daemon := range daemons {
+		// the same container ID in both daemons, e.g. restored from the same checkpoint
+		sampler := newTestSampler(t, []container.Summary{{
+			ID:     "app",
+			State:  container.StateRunning,
+			Labels: map[string]string{"com.docker.compose.project": "shop", "com.docker.compose.service": "web"},
+		}}, nil, allMetrics())
+		sampler.daemon = daemon
+		require.NoError(t, sampler.SampleAll(context.Background(), i, system.Info{}))
+	}
+
+	require.Len(t, i.Entities, 3, "a container entity per daemon plus the local entity")
+	for _, daemon := range daemons {
+		entity, err := i.Entity("app", "docker", integration.NewIDAttribute("daemonId", daemon.ID))
+		require.NoError(t, err)
+		require.Len(t, entity.Metrics, 1)
+		assert.Equal(t, daemon.Host, entity.Metrics[0].Metrics["dockerHost"])
+		assert.Equal(t, daemon.ID, entity.Metrics[0].Metrics["daemonId"])
+	}
+
+	samples := i.LocalEntity().Metrics
+	require.Len(t, samples, 2)
+	assert.Equal(t, "rootful", samples[0].Metrics["daemonId"])
+	assert.Equal(t, "rootless", samples[1].Metrics["daemonId"])
+}
+
+func TestDaemonStoreName(t *testing.T) {
+	assert.Equal(t, "container_cpus", Daemon{}.storeName())
+	assert.Equal(t, "container_cpus_ABCD_1234", Daemon{Host: "tcp://10.0.0.1", ID: "ABCD:1234"}.storeName())
+	assert.Equal(t, "container_cpus_tcp___10_0_0_1_2375", Daemon{Host: "tcp://10.0.0.1:2375"}.storeName())
+}
+
+// versionedMocker is a Docker mock aware of the negotiated API version.
+type versionedMocker struct {
+	mocker
+}
+
+func (m *versionedMocker) APIVersion() string { return "1.43" }
+
+func (m *versionedMocker) APIFeatures() raw.APIFeatures { return raw.NewAPIFeatures("1.43") }
+
+func TestDockerDaemonSample(t *testing.T) {
+	docker := &versionedMocker{}
+	docker.On("ContainerList", mock.Anything, mock.Anything).Return([]container.Summary{}, nil)
+
+	args := config.ArgumentList{}
+	args.TempDir = t.TempDir()
+	args.ExitedContainersTTL = "24h"
+	args.InventoryEnvValues = "hide"
+	sampler, err := NewSampler(&mockFetcher{}, docker, args, config.File{},
+		Daemon{Host: "tcp://10.0.0.1:2376", ID: "daemon-1"})
+	require.NoError(t, err)
+
+	i, err := integration.New("test", "test-version")
+	require.NoError(t, err)
+	require.NoError(t, sampler.SampleAll(context.Background(), i, system.Info{
+		ID:                "daemon-1",
+		ServerVersion:     "24.0.7",
+		ContainersRunning: 3,
+		ContainersStopped: 1,
+		Images:            10,
+	}))
+
+	samples := i.LocalEntity().Metrics
+	require.Len(t, samples, 1)
+	assert.Equal(t, "DockerDaemonSample", samples[0].Metrics["event_type"])
+	assert.Equal(t, "1.43", samples[0].Metrics["apiVersion"])
+	assert.Equal(t, "24.0.7", samples[0].Metrics["dockerVersion"])
+	assert.Equal(t, "tcp://10.0.0.1:2376", samples[0].Metrics["dockerHost"])
+	assert.Equal(t, float64(3), samples[0].Metrics["containersRunning"])
+	assert.Equal(t, float64(1), samples[0].Metrics["containersStopped"])
+	assert.Equal(t, float64(10), samples[0].Metrics["images"])
+}
+
+// ecsMocker is a Docker mock providing ECS task metadata, as the Fargate inspector does.
+// ecsMocker is a task metadata client returning a fixed task.
+type ecsMocker struct {
+	task aws.TaskResponse
+}
+
+func (m *ecsMocker) TaskMetadata(_ context.Context) (aws.TaskResponse, error) {
+	return m.task, nil
+}
+
+func TestEcsTaskSample(t *testing.T) {
+	cpuLimit, memoryLimit := 0.5, int64(1)
+	pullStarted := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	pullStopped := pullStarted.Add(3 * time.Second)
+	created, started := pullStopped.Add(time.Second), pullStopped.Add(2*time.Second)
+	sidecarStarted, executionStopped := started.Add(time.Second), pullStarted.Add(time.Minute)
+
+	ecs := &ecsMocker{task: aws.TaskResponse{
+		Cluster:                 "arn:aws:ecs:us-east-1:123456789012:cluster/prod",
+		TaskARN:                 "arn:aws:ecs:us-east-1:123456789012:task/prod/f05a5672397746638bc201a252c5bb75",
+		Family:                  "api",
+		Revision:                "7",
+		ServiceName:             "api-service",
+		DesiredStatus:           "RUNNING",
+		KnownStatus:             "RUNNING",
+		AvailabilityZone:        "us-east-1a",
+		Limits:                  &aws.LimitsResponse{CPU: &cpuLimit, Memory: &memoryLimit},
+		PullStartedAt:           &pullStarted,
+		PullStoppedAt:           &pullStopped,
+		ExecutionStoppedAt:      &executionStopped,
+		EphemeralStorageMetrics: &aws.EphemeralStorageMetrics{Utilized: 5124, Reserved: 20496},
+		Containers: []aws.ContainerResponse{
+			{ID: "app", KnownStatus: "RUNNING", CreatedAt: &created, StartedAt: &started},
+			{ID: "sidecar", KnownStatus: "RUNNING", CreatedAt: &created, StartedAt: &sidecarStarted},
+			{ID: "init", KnownStatus: "PULLED"},
+		},
+	}}
+	sampler := newTestSampler(t, []container.Summary{
+		{ID: "app", State: container.StateRunning},
+		{ID: "sidecar", State: container.StateRunning},
+	}, nil, allMetrics())
+	sampler.ecs = ecs
+
+	i := sampleAll(t, sampler)
+
+	samples := i.LocalEntity().Metrics
+	require.Len(t, samples, 1)
+	task := samples[0].Metrics
+	assert.Equal(t, "EcsTaskSample", task["event_type"])
+	assert.Equal(t, ecs.task.TaskARN, task["ecsTaskArn"])
+	assert.Equal(t, "prod", task["ecsClusterName"])
+	assert.Equal(t, "api", task["ecsTaskDefinitionFamily"])
+	assert.Equal(t, "7", task["ecsTaskDefinitionVersion"])
+	assert.Equal(t, "us-east-1", task["awsRegion"])
+	assert.Equal(t, "us-east-1a", task["awsAvailabilityZone"])
+	assert.Equal(t, "123456789012", task["awsAccountId"])
+	assert.Equal(t, "api-service", task["ecsServiceName"])
+	assert.Equal(t, "RUNNING", task["desiredStatus"])
+	assert.Equal(t, "RUNNING", task["knownStatus"])
+	assert.Equal(t, 0.5, task["cpuLimitCores"])
+	assert.Equal(t, float64(mebibyte), task["memorySizeLimitBytes"])
+	assert.Equal(t, float64(2*nonZeroUint), task["memoryUsageBytes"])
+	assert.InDelta(t, float64(2*nonZeroUint)/mebibyte*100, task["memoryUsageLimitPercent"], 1e-9)
+	assert.Equal(t, float64(3), task["imagePullDurationSeconds"])
+	assert.Equal(t, float64(6), task["startupDurationSeconds"])
+	assert.Equal(t, float64(60), task["lifetimeSeconds"])
+	assert.Equal(t, float64(3), task["containers"])
+	assert.Equal(t, float64(2), task["containersRunning"])
+	assert.Equal(t, float64(1), task["containersPending"])
+	assert.Equal(t, float64(0), task["containersStopped"])
+	assert.Equal(t, float64(5124*mebibyte), task["ephemeralStorageUsedBytes"])
+	assert.Equal(t, float64(20496*mebibyte), task["ephemeralStorageReservedBytes"])
+	assert.InDelta(t, 25.0, task["ephemeralStorageUsagePercent"], 0.01)
+}
+
+func TestNetworkMetricsReportedRates(t *testing.T) {
+	rxRate, txRate := 118.3, 97.6
+	entries := (&ContainerSampler{}).networkMetrics(&biz.Network{
+		RxBytes:          nonZero,
+		TxBytes:          nonZero,
+		RxBytesPerSecond: &rxRate,
+		TxBytesPerSecond: &txRate,
+	})
+
+	assert.Contains(t, entries, metricRxBytesPerSecond(rxRate), "reported rates are used as gauges")
+	assert.Contains(t, entries, metricTxBytesPerSecond(txRate))
+
+	entries = (&ContainerSampler{}).networkMetrics(&biz.Network{RxBytes: nonZero})
+	assert.Contains(t, entries, metricRxBytes(nonZero))
+	for _, e := range entries {
+		assert.NotEqual(t, "networkRxBytesPerSecond", e.Name, "there is no rate without a previous sampling")
+	}
+}
+
+func TestStoppedContainers(t *testing.T) {
+	finishedAt := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339Nano)
+	i := sampleAll(t, newTestSampler(t, []container.Summary{
+		{ID: "sidecar", State: container.StateExited, Status: "STOPPED", Labels: map[string]string{
+			"com.newrelic.nri-docker.desired-status": "RUNNING",
+		}},
+		{ID: "init", State: container.StateExited, Status: "STOPPED"},
+	}, map[string]container.InspectResponse{
+		"sidecar": {ID: "sidecar", State: &container.State{Status: container.StateExited, ExitCode: 1, FinishedAt: finishedAt}},
+		"init": {ID: "init", State: &container.State{
+			Status:     container.StateExited,
+			FinishedAt: time.Now().Add(-2 * time.Hour).Format(time.RFC3339Nano),
+		}},
+	}, raw.Metrics{}))
+
+	require.Len(t, i.Entities, 1, "containers exited before the TTL are not reported")
+	sidecar := i.Entities[0].Metrics[0].Metrics
+	assert.Equal(t, "sidecar", sidecar["containerId"])
+	assert.Equal(t, float64(1), sidecar["exitCode"])
+	assert.Equal(t, finishedAt, sidecar["finishedAt"])
+	assert.Equal(t, "Error", sidecar["stopReason"])
+	assert.Equal(t, "RUNNING", sidecar["desiredStatus"])
+	assert.NotContains(t, sidecar, "cpuPercent")
+}
+
+func TestSampleAllLogBytesWrittenPerSecond(t *testing.T) {
+	hostRoot := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(hostRoot, "proc"), 0o755))
+	logPath := "/var/lib/docker/containers/app/app-json.log"
+	require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(hostRoot, logPath)), 0o755))
+	writeLog := func(size int) {
+		require.NoError(t, os.WriteFile(filepath.Join(hostRoot, logPath), make([]byte, size), 0o600))
+	}
+
+	sampler := newTestSampler(t, []container.Summary{{ID: "app", State: container.StateRunning}}, map[string]container.InspectResponse{
+		"app": {
+			ID:         "app",
+			LogPath:    logPath,
+			State:      &container.State{Status: "running"},
+			HostConfig: &container.HostConfig{LogConfig: container.LogConfig{Type: "json-file"}},
+		},
+	}, raw.Metrics{})
+
+	// the samples are taken 10 seconds apart
+	fetcher := &mockFetcher{}
+	start := time.Now()
+	for n := 0; n < 3; n++ {
+		stats := allMetrics()
+		stats.Time = start.Add(time.Duration(n) * 10 * time.Second)
+		usage := uint64(n) * nonZeroUint
+		stats.CPU = raw.CPU{TotalUsage: usage, UsageInUsermode: &usage, UsageInKernelmode: &usage, SystemUsage: usage}
+		fetcher.On("Fetch", mock.Anything).Return(stats, nil).Once()
+	}
+	processor := biz.NewProcessor(persist.NewInMemoryStore(), fetcher, sampler.docker, 0)
+	processor.WithHostRoot(hostRoot)
+	sampler.metrics = processor
+
+	writeLog(1000)
+	metrics := sampleAll(t, sampler).Entities[0].Metrics[0].Metrics
+	assert.Equal(t, float64(1000), metrics["logFilesSizeBytes"])
+	assert.NotContains(t, metrics, "logBytesWrittenPerSecond", "the rate is reported from the second sample")
+
+	writeLog(3000)
+	metrics = sampleAll(t, sampler).Entities[0].Metrics[0].Metrics
+	assert.Equal(t, float64(200), metrics["logBytesWrittenPerSecond"])
+
+	// the active file is rotated, so the rate is reset instead of being negative
+	require.NoError(t, os.Rename(filepath.Join(hostRoot, logPath), filepath.Join(hostRoot, logPath+".1")))
+	writeLog(100)
+	metrics = sampleAll(t, sampler).Entities[0].Metrics[0].Metrics
+	assert.Equal(t, float64(3100), metrics["logFilesSizeBytes"])
+	assert.NotContains(t, metrics, "logBytesWrittenPerSecond")
+}
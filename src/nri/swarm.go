@@ -0,0 +1,171 @@
+package nri
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/moby/moby/api/types/swarm"
+	"github.com/moby/moby/api/types/system"
+	"github.com/newrelic/infra-integrations-sdk/v3/data/attribute"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+
+	"github.com/newrelic/nri-docker/src/raw"
+)
+
+const (
+	swarmServiceSampleName = "SwarmServiceSample"
+
+	swarmTaskIDLabel = "com.docker.swarm.task.id"
+
+	attrSwarmServiceID   = "swarmServiceId"
+	attrSwarmServiceName = "swarmServiceName"
+	attrSwarmServiceMode = "swarmServiceMode"
+)
+
+// swarmMetadata holds the Swarm cluster state used to decorate the containers that are Swarm tasks.
+type swarmMetadata struct {
+	tasks         map[string]swarm.Task
+	services      map[string]swarm.Service
+	nodeHostnames map[string]string
+	// isLeader is true if the current node is the Swarm leader, the only one reporting the services samples
+	// to avoid duplicates across managers.
+	isLeader bool
+}
+
+// fetchSwarmMetadata returns the Swarm cluster state if the daemon is a Swarm manager, nil otherwise.
+func fetchSwarmMetadata(ctx context.Context, client raw.SwarmClient, info system.Info) *swarmMetadata {
+	if client == nil || !info.Swarm.ControlAvailable {
+		return nil
+	}
+
+	services, err := client.ServiceList(ctx)
+	if err != nil {
+		log.Warn("listing Swarm services: %v", err)
+		return nil
+	}
+	tasks, err := client.TaskList(ctx)
+	if err != nil {
+		log.Warn("listing Swarm tasks: %v", err)
+		return nil
+	}
+	nodes, err := client.NodeList(ctx)
+	if err != nil {
+		log.Warn("listing Swarm nodes: %v", err)
+		return nil
+	}
+
+	m := &swarmMetadata{
+		tasks:         make(map[string]swarm.Task, len(tasks)),
+		services:      make(map[string]swarm.Service, len(services)),
+		nodeHostnames: make(map[string]string, len(nodes)),
+	}
+	for _, s := range services {
+		m.services[s.ID] = s
+	}
+	for _, t := range tasks {
+		m.tasks[t.ID] = t
+	}
+	for _, n := range nodes {
+		m.nodeHostnames[n.ID] = n.Description.Hostname
+		if n.ID == info.Swarm.NodeID && n.ManagerStatus != nil && n.ManagerStatus.Leader {
+			m.isLeader = true
+		}
+	}
+	return m
+}
+
+// attributes returns the Swarm service, task and node metadata of the container with the given labels.
+func (m *swarmMetadata) attributes(labels map[string]string) []entry {
+	if m == nil {
+		return nil
+	}
+	task, ok := m.tasks[labels[swarmTaskIDLabel]]
+	if !ok {
+		return nil
+	}
+
+	entries := []entry{
+		metricSwarmTaskState(string(task.Status.State)),
+		metricSwarmTaskDesiredState(string(task.DesiredState)),
+	}
+	if task.Slot != 0 {
+		// global services tasks have no slot
+		entries = append(entries, metricSwarmTaskSlot(strconv.Itoa(task.Slot)))
+	}
+	if service, ok := m.services[task.ServiceID]; ok {
+		entries = append(entries,
+			metricSwarmServiceName(service.Spec.Name),
+			metricSwarmServiceMode(serviceMode(service)),
+		)
+	}
+	if hostname := m.nodeHostnames[task.NodeID]; hostname != "" {
+		entries = append(entries, metricSwarmNodeHostname(hostname))
+	}
+	return entries
+}
+
+// populateServiceSamples creates a SwarmServiceSample for each of the Swarm services, only if the node is the leader.
+func (m *swarmMetadata) populateServiceSamples(i *integration.Integration, attributes ...attribute.Attribute) {
+	if m == nil || !m.isLeader {
+		return
+	}
+
+	ids := make([]string, 0, len(m.services))
+	for id := range m.services {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		service := m.services[id]
+		desired, running := m.replicas(service)
+		ms := i.LocalEntity().NewMetricSet(swarmServiceSampleName, append([]attribute.Attribute{
+			attribute.Attr(attrSwarmServiceID, service.ID),
+			attribute.Attr(attrSwarmServiceName, service.Spec.Name),
+			attribute.Attr(attrSwarmServiceMode, serviceMode(service)),
+		}, attributes...)...)
+		populate(ms, []entry{
+			metricDesiredReplicas(desired),
+			metricRunningReplicas(running),
+		})
+	}
+}
+
+// replicas returns the desired and running tasks of a service. They are computed from the tasks list when the
+// daemon does not report the service status.
+func (m *swarmMetadata) replicas(service swarm.Service) (desired, running uint64) {
+	if service.ServiceStatus != nil {
+		return service.ServiceStatus.DesiredTasks, service.ServiceStatus.RunningTasks
+	}
+	for _, t := range m.tasks {
+		if t.ServiceID != service.ID {
+			continue
+		}
+		if t.DesiredState == swarm.TaskStateRunning {
+			desired++
+		}
+		if t.Status.State == swarm.TaskStateRunning {
+			running++
+		}
+	}
+	if service.Spec.Mode.Replicated != nil && service.Spec.Mode.Replicated.Replicas != nil {
+		desired = *service.Spec.Mode.Replicated.Replicas
+	}
+	return desired, running
+}
+
+func serviceMode(service swarm.Service) string {
+	mode := service.Spec.Mode
+	switch {
+	case mode.Global != nil:
+		return "global"
+	case mode.ReplicatedJob != nil:
+		return "replicated-job"
+	case mode.GlobalJob != nil:
+		return "global-job"
+	default:
+		return "replicated"
+	}
+}
@@ -0,0 +1,156 @@
+package nri
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/moby/moby/api/types/swarm"
+	"github.com/moby/moby/api/types/system"
+	"github.com/moby/moby/client"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/nri-docker/src/raw"
+)
+
+// startSwarmAPIStub starts a fake docker API server responding to the Swarm endpoints.
+func startSwarmAPIStub(t *testing.T) *raw.DockerClientWrapper {
+	t.Helper()
+
+	replicas := uint64(3)
+	responses := map[string]interface{}{
+		"/services": []swarm.Service{
+			{
+				ID: "web-service-id",
+				Spec: swarm.ServiceSpec{
+					Annotations: swarm.Annotations{Name: "web"},
+					Mode:        swarm.ServiceMode{Replicated: &swarm.ReplicatedService{Replicas: &replicas}},
+				},
+				ServiceStatus: &swarm.ServiceStatus{DesiredTasks: 3, RunningTasks: 2},
+			},
+			{
+				ID: "agent-service-id",
+				Spec: swarm.ServiceSpec{
+					Annotations: swarm.Annotations{Name: "agent"},
+					Mode:        swarm.ServiceMode{Global: &swarm.GlobalService{}},
+				},
+			},
+		},
+		"/tasks": []swarm.Task{
+			{
+				ID:           "web-task-id",
+				ServiceID:    "web-service-id",
+				Slot:         2,
+				NodeID:       "node-1",
+				DesiredState: swarm.TaskStateRunning,
+				Status:       swarm.TaskStatus{State: swarm.TaskStateRunning},
+			},
+			{
+				ID:           "agent-task-id",
+				ServiceID:    "agent-service-id",
+				NodeID:       "node-2",
+				DesiredState: swarm.TaskStateRunning,
+				Status:       swarm.TaskStatus{State: swarm.TaskStateRunning},
+			},
+			{
+				ID:           "agent-old-task-id",
+				ServiceID:    "agent-service-id",
+				NodeID:       "node-1",
+				DesiredState: swarm.TaskStateShutdown,
+				Status:       swarm.TaskStatus{State: swarm.TaskStateFailed},
+			},
+		},
+		"/nodes": []swarm.Node{
+			{
+				ID:            "node-1",
+				Description:   swarm.NodeDescription{Hostname: "manager-1"},
+				ManagerStatus: &swarm.ManagerStatus{Leader: true},
+			},
+			{
+				ID:          "node-2",
+				Description: swarm.NodeDescription{Hostname: "worker-1"},
+			},
+		},
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// paths are prefixed by the API version, e.g. /v1.44/services
+		path := r.URL.Path[strings.Index(r.URL.Path[1:], "/")+1:]
+		response, ok := responses[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(response))
+	}))
+	t.Cleanup(ts.Close)
+
+	c, err := client.NewClientWithOpts(client.WithHost("tcp://"+ts.Listener.Addr().String()), client.WithVersion("1.44"))
+	require.NoError(t, err)
+	return raw.NewDockerClientWrapper(c)
+}
+
+func TestSwarmMetadata(t *testing.T) {
+	docker := startSwarmAPIStub(t)
+	info := system.Info{Swarm: swarm.Info{NodeID: "node-1", ControlAvailable: true}}
+
+	meta := fetchSwarmMetadata(context.Background(), docker, info)
+	require.NotNil(t, meta)
+	assert.True(t, meta.isLeader)
+
+	assert.ElementsMatch(t, []entry{
+		metricSwarmTaskState("running"),
+		metricSwarmTaskDesiredState("running"),
+		metricSwarmTaskSlot("2"),
+		metricSwarmServiceName("web"),
+		metricSwarmServiceMode("replicated"),
+		metricSwarmNodeHostname("manager-1"),
+	}, meta.attributes(map[string]string{"com.docker.swarm.task.id": "web-task-id"}))
+
+	assert.ElementsMatch(t, []entry{
+		metricSwarmTaskState("running"),
+		metricSwarmTaskDesiredState("running"),
+		metricSwarmServiceName("agent"),
+		metricSwarmServiceMode("global"),
+		metricSwarmNodeHostname("worker-1"),
+	}, meta.attributes(map[string]string{"com.docker.swarm.task.id": "agent-task-id"}))
+
+	assert.Empty(t, meta.attributes(map[string]string{"com.docker.swarm.task.id": "unknown"}))
+	assert.Empty(t, meta.attributes(map[string]string{}))
+
+	i, err := integration.New("test", "test-version")
+	require.NoError(t, err)
+	meta.populateServiceSamples(i)
+
+	samples := i.LocalEntity().Metrics
+	require.Len(t, samples, 2)
+	assert.Equal(t, "SwarmServiceSample", samples[0].Metrics["event_type"])
+	assert.Equal(t, "agent", samples[0].Metrics["swarmServiceName"])
+	assert.Equal(t, "global", samples[0].Metrics["swarmServiceMode"])
+	assert.Equal(t, float64(1), samples[0].Metrics["desiredReplicas"], "computed from the tasks when status is missing")
+	assert.Equal(t, float64(1), samples[0].Metrics["runningReplicas"])
+	assert.Equal(t, "web", samples[1].Metrics["swarmServiceName"])
+	assert.Equal(t, float64(3), samples[1].Metrics["desiredReplicas"])
+	assert.Equal(t, float64(2), samples[1].Metrics["runningReplicas"])
+}
+
+func TestSwarmMetadataNotManager(t *testing.T) {
+	docker := startSwarmAPIStub(t)
+
+	assert.Nil(t, fetchSwarmMetadata(context.Background(), docker, system.Info{}))
+	assert.Nil(t, fetchSwarmMetadata(context.Background(), nil, system.Info{Swarm: swarm.Info{ControlAvailable: true}}))
+
+	// a manager that is not the leader decorates containers but does not report services
+	meta := fetchSwarmMetadata(context.Background(), docker, system.Info{Swarm: swarm.Info{NodeID: "node-2", ControlAvailable: true}})
+	require.NotNil(t, meta)
+	i, err := integration.New("test", "test-version")
+	require.NoError(t, err)
+	meta.populateServiceSamples(i)
+	assert.Empty(t, i.LocalEntity().Metrics)
+}
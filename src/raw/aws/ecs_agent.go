@@ -0,0 +1,276 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	containerTypes "github.com/moby/moby/api/types/container"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+
+	"github.com/newrelic/nri-docker/src/raw"
+)
+
+const (
+	// synthetic labels with the ECS on EC2 info
+	serviceNameLabel          = "com.newrelic.nri-docker.service-name"
+	containerInstanceARNLabel = "com.newrelic.nri-docker.container-instance-arn"
+	availabilityZoneLabel     = "com.newrelic.nri-docker.availability-zone"
+)
+
+// AgentMetadata defines the schema for the /v1/metadata response of the ECS agent introspection API.
+type AgentMetadata struct {
+	Cluster              string `json:"Cluster"`
+	ContainerInstanceArn string `json:"ContainerInstanceArn"`
+	Version              string `json:"Version"`
+}
+
+// AgentTasks defines the schema for the /v1/tasks response of the ECS agent introspection API.
+type AgentTasks struct {
+	Tasks []AgentTask `json:"Tasks"`
+}
+
+// AgentTask defines the schema for the tasks of the ECS agent introspection API.
+type AgentTask struct {
+	Arn           string           `json:"Arn"`
+	DesiredStatus string           `json:"DesiredStatus"`
+	KnownStatus   string           `json:"KnownStatus"`
+	Family        string           `json:"Family"`
+	Version       string           `json:"Version"`
+	Containers    []AgentContainer `json:"Containers"`
+}
+
+// AgentContainer defines the schema for the task containers of the ECS agent introspection API.
+type AgentContainer struct {
+	DockerID   string `json:"DockerId"`
+	DockerName string `json:"DockerName"`
+	Name       string `json:"Name"`
+}
+
+// ECSAgentClient queries the introspection API of the ECS agent running in the container instance.
+type ECSAgentClient struct {
+	baseURL  *url.URL
+	metadata *metadataClient
+}
+
+// NewECSAgentClient creates a new ECSAgentClient for the introspection API in the given URL.
+func NewECSAgentClient(baseURL string) (*ECSAgentClient, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("parsing ECS agent URL %q: %w", baseURL, err)
+	}
+	return &ECSAgentClient{baseURL: u, metadata: sharedMetadataClient}, nil
+}
+
+// Metadata returns the metadata of the container instance.
+func (c *ECSAgentClient) Metadata(ctx context.Context) (AgentMetadata, error) {
+	var metadata AgentMetadata
+	err := c.get(ctx, "/v1/metadata", &metadata)
+	return metadata, err
+}
+
+// Tasks returns the tasks the agent manages in the container instance.
+func (c *ECSAgentClient) Tasks(ctx context.Context) ([]AgentTask, error) {
+	var tasks AgentTasks
+	err := c.get(ctx, "/v1/tasks", &tasks)
+	return tasks.Tasks, err
+}
+
+func (c *ECSAgentClient) get(ctx context.Context, path string, v interface{}) error {
+	endpoint := c.baseURL.String() + path
+	response, err := c.metadata.get(ctx, endpoint)
+	if err != nil {
+		return fmt.Errorf("error when sending request to ECS agent introspection endpoint (%s): %v", endpoint, err)
+	}
+	if err := json.Unmarshal(response, v); err != nil {
+		return fmt.Errorf("error unmarshalling ECS agent response from %s: %v", endpoint, err)
+	}
+	return nil
+}
+
+// ECSInspector decorates the containers of a Docker daemon running in an ECS container instance with the
+// metadata of the ECS agent and of the tasks they belong to, which are added as synthetic labels. The metadata
+// is fetched once, the first time the containers are listed.
+type ECSInspector struct {
+	docker   raw.DockerClient
+	agent    *ECSAgentClient
+	metadata *metadataClient
+
+	loaded bool
+	// instance holds the metadata of the container instance, and tasks the metadata of the tasks indexed by the
+	// ID of their containers, which is nil if the task metadata endpoint could not be queried.
+	instance AgentMetadata
+	tasks    map[string]*TaskResponse
+}
+
+// NewECSInspector creates a new ECSInspector for the containers of the given Docker client.
+func NewECSInspector(docker raw.DockerClient, agent *ECSAgentClient) *ECSInspector {
+	return &ECSInspector{docker: docker, agent: agent, metadata: sharedMetadataClient}
+}
+
+// APIVersion returns the API version of the decorated client, if known.
+func (i *ECSInspector) APIVersion() string {
+	if versioned, ok := i.docker.(raw.VersionedClient); ok {
+		return versioned.APIVersion()
+	}
+	return ""
+}
+
+// APIFeatures returns the API features of the decorated client, if known, or all of them otherwise.
+func (i *ECSInspector) APIFeatures() raw.APIFeatures {
+	if versioned, ok := i.docker.(raw.VersionedClient); ok {
+		return versioned.APIFeatures()
+	}
+	return raw.NewAPIFeatures("")
+}
+
+// ContainerList lists the containers of the Docker daemon, adding the ECS labels to the ones belonging to a task.
+func (i *ECSInspector) ContainerList(ctx context.Context, all bool) ([]containerTypes.Summary, error) {
+	containers, err := i.docker.ContainerList(ctx, all)
+	if err != nil {
+		return nil, err
+	}
+	i.load(ctx)
+	for index := range containers {
+		containers[index].Labels = i.labels(containers[index].ID, containers[index].Labels)
+	}
+	return containers, nil
+}
+
+// ContainerInspect inspects the container, adding the ECS labels and falling back to the task limits for the
+// resources which are not limited at container level.
+func (i *ECSInspector) ContainerInspect(ctx context.Context, containerID string) (containerTypes.InspectResponse, error) {
+	inspect, err := i.docker.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return inspect, err
+	}
+	i.load(ctx)
+
+	task, ok := i.tasks[containerID]
+	if !ok {
+		return inspect, nil
+	}
+	if inspect.Config != nil {
+		inspect.Config.Labels = i.labels(containerID, inspect.Config.Labels)
+	}
+	if inspect.HostConfig != nil && task != nil {
+		withTaskLimits(&inspect.HostConfig.Resources, task.Limits)
+	}
+	return inspect, nil
+}
+
+// load fetches the container instance and tasks metadata, unless it was already fetched. Errors are logged, so
+// containers are still reported without the ECS metadata.
+func (i *ECSInspector) load(ctx context.Context) {
+	if i.loaded {
+		return
+	}
+	i.loaded = true
+	i.tasks = map[string]*TaskResponse{}
+
+	instance, err := i.agent.Metadata(ctx)
+	if err != nil {
+		log.Warn("fetching ECS container instance metadata: %v", err)
+	}
+	i.instance = instance
+
+	tasks, err := i.agent.Tasks(ctx)
+	if err != nil {
+		log.Warn("fetching ECS tasks: %v", err)
+		return
+	}
+	for _, t := range tasks {
+		metadata := i.taskMetadata(ctx, t)
+		for _, c := range t.Containers {
+			i.tasks[c.DockerID] = metadata
+		}
+	}
+	log.Debug("fetched the metadata of %d ECS tasks", len(tasks))
+}
+
+// taskMetadata returns the metadata of the task from the task metadata endpoint, whose URL is taken from the
+// environment of any of its containers, or nil if it is not available.
+func (i *ECSInspector) taskMetadata(ctx context.Context, task AgentTask) *TaskResponse {
+	for _, c := range task.Containers {
+		inspect, err := i.docker.ContainerInspect(ctx, c.DockerID)
+		if err != nil || inspect.Config == nil {
+			continue
+		}
+		metadataURI := containerEnv(inspect.Config.Env, containerMetadataEnvVarV4)
+		if metadataURI == "" {
+			continue
+		}
+
+		endpoint := TaskMetadataEndpoint(metadataURI)
+		// a single attempt is made, as the endpoint is not available once the task is stopped
+		response, err := i.metadata.getOnce(ctx, endpoint)
+		if err != nil {
+			log.Debug("fetching metadata of ECS task %s: %v", task.Arn, err)
+			return nil
+		}
+		var metadata TaskResponse
+		if err := json.Unmarshal(response, &metadata); err != nil {
+			log.Debug("error unmarshalling metadata of ECS task %s: %v", task.Arn, err)
+			return nil
+		}
+		return &metadata
+	}
+	return nil
+}
+
+// labels returns the container labels, plus synthetic labels with the ECS info if the container belongs to a task.
+func (i *ECSInspector) labels(containerID string, containerLabels map[string]string) map[string]string {
+	task, ok := i.tasks[containerID]
+	if !ok {
+		return containerLabels
+	}
+
+	labels := make(map[string]string, len(containerLabels))
+	for k, v := range containerLabels {
+		labels[k] = v
+	}
+	labels = processECSLabels(labels, raw.EC2LaunchType)
+
+	synthetic := map[string]string{containerInstanceARNLabel: i.instance.ContainerInstanceArn}
+	if task != nil {
+		synthetic[serviceNameLabel] = task.ServiceName
+		synthetic[availabilityZoneLabel] = task.AvailabilityZone
+	}
+	for label, value := range synthetic {
+		if value != "" {
+			labels[label] = value
+		}
+	}
+	return labels
+}
+
+// withTaskLimits sets the task CPU and memory limits to the resources which are not limited at container level.
+// The task CPU is the hard CPU limit of its containers, unless they are limited to less.
+func withTaskLimits(resources *containerTypes.Resources, taskLimits *LimitsResponse) {
+	if taskLimits == nil {
+		return
+	}
+	if resources.CPUQuota == 0 && taskLimits.CPU != nil {
+		// task CPU is set in vCPUs
+		taskNanoCPUs := int64(*taskLimits.CPU * 1e9)
+		if resources.NanoCPUs == 0 || resources.NanoCPUs > taskNanoCPUs {
+			resources.NanoCPUs = taskNanoCPUs
+		}
+	}
+	if resources.Memory == 0 && taskLimits.Memory != nil {
+		// memory is set in MiB
+		resources.Memory = *taskLimits.Memory * mebibyte
+	}
+}
+
+// containerEnv returns the value of the given variable in the container environment.
+func containerEnv(env []string, name string) string {
+	for _, e := range env {
+		if value, ok := strings.CutPrefix(e, name+"="); ok {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,151 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	containerTypes "github.com/moby/moby/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	ecsTestTaskARN     = "arn:aws:ecs:us-east-1:123456789012:task/prod/f05a5672397746638bc201a252c5bb75"
+	ecsTestInstanceARN = "arn:aws:ecs:us-east-1:123456789012:container-instance/prod/1f73d099"
+)
+
+// dockerStub is a Docker client with a fixed set of containers.
+type dockerStub map[string]containerTypes.InspectResponse
+
+func (d dockerStub) ContainerList(_ context.Context, _ bool) ([]containerTypes.Summary, error) {
+	var containers []containerTypes.Summary
+	for id, inspect := range d {
+		containers = append(containers, containerTypes.Summary{ID: id, Labels: inspect.Config.Labels})
+	}
+	return containers, nil
+}
+
+func (d dockerStub) ContainerInspect(_ context.Context, containerID string) (containerTypes.InspectResponse, error) {
+	inspect, ok := d[containerID]
+	if !ok {
+		return inspect, fmt.Errorf("no such container: %s", containerID)
+	}
+	// the inspect responses are copied, as the inspector modifies them
+	config, hostConfig := *inspect.Config, *inspect.HostConfig
+	inspect.Config, inspect.HostConfig = &config, &hostConfig
+	return inspect, nil
+}
+
+func startECSAgentStub(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/metadata", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `{"Cluster":"prod","ContainerInstanceArn":%q,"Version":"Amazon ECS Agent - v1.89.0"}`, ecsTestInstanceARN)
+	})
+	mux.HandleFunc("/v1/tasks", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `{"Tasks":[
+			{"Arn":%q,"DesiredStatus":"RUNNING","KnownStatus":"RUNNING","Family":"api","Version":"7",
+			 "Containers":[{"DockerId":"app","DockerName":"ecs-api-7-app","Name":"app"}]},
+			{"Arn":"arn:aws:ecs:us-east-1:123456789012:task/prod/0b69d5c0d7f1","KnownStatus":"RUNNING",
+			 "Containers":[{"DockerId":"batch","Name":"batch"}]}]}`, ecsTestTaskARN)
+	})
+	mux.HandleFunc("/v4/app-endpoint/task", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `{"TaskARN":%q,"ServiceName":"api-service","LaunchType":"EC2","AvailabilityZone":"us-east-1a",
+			"Limits":{"CPU":0.5,"Memory":512}}`, ecsTestTaskARN)
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestECSInspector(t *testing.T) {
+	ts := startECSAgentStub(t)
+	docker := dockerStub{
+		"app": {
+			ID: "app",
+			Config: &containerTypes.Config{
+				Labels: map[string]string{"com.amazonaws.ecs.cluster": "prod", "com.amazonaws.ecs.task-arn": ecsTestTaskARN},
+				Env:    []string{"PATH=/bin", "ECS_CONTAINER_METADATA_URI_V4=" + ts.URL + "/v4/app-endpoint"},
+			},
+			HostConfig: &containerTypes.HostConfig{},
+		},
+		// the metadata endpoint of the task is not available
+		"batch": {
+			ID:         "batch",
+			Config:     &containerTypes.Config{},
+			HostConfig: &containerTypes.HostConfig{Resources: containerTypes.Resources{Memory: 1024}},
+		},
+		"standalone": {
+			ID:         "standalone",
+			Config:     &containerTypes.Config{Labels: map[string]string{"app": "redis"}},
+			HostConfig: &containerTypes.HostConfig{},
+		},
+	}
+
+	agent, err := NewECSAgentClient(ts.URL + "/")
+	require.NoError(t, err)
+	inspector := NewECSInspector(docker, agent)
+
+	containers, err := inspector.ContainerList(context.Background(), true)
+	require.NoError(t, err)
+	labels := map[string]map[string]string{}
+	for _, c := range containers {
+		labels[c.ID] = c.Labels
+	}
+
+	assert.Equal(t, map[string]string{
+		"com.amazonaws.ecs.cluster":                      "prod",
+		"com.amazonaws.ecs.task-arn":                     ecsTestTaskARN,
+		"com.newrelic.nri-docker.aws-region":             "us-east-1",
+		"com.newrelic.nri-docker.account-id":             "123456789012",
+		"com.newrelic.nri-docker.launch-type":            "ec2",
+		"com.newrelic.nri-docker.container-instance-arn": ecsTestInstanceARN,
+		"com.newrelic.nri-docker.service-name":           "api-service",
+		"com.newrelic.nri-docker.availability-zone":      "us-east-1a",
+	}, labels["app"])
+	assert.Equal(t, map[string]string{
+		"com.newrelic.nri-docker.launch-type":            "ec2",
+		"com.newrelic.nri-docker.container-instance-arn": ecsTestInstanceARN,
+	}, labels["batch"])
+	assert.Equal(t, map[string]string{"app": "redis"}, labels["standalone"], "containers out of tasks are not decorated")
+
+	app, err := inspector.ContainerInspect(context.Background(), "app")
+	require.NoError(t, err)
+	assert.Equal(t, "api-service", app.Config.Labels["com.newrelic.nri-docker.service-name"])
+	assert.Equal(t, int64(5e8), app.HostConfig.NanoCPUs, "task limits are used when the container has none")
+	assert.Equal(t, int64(512*mebibyte), app.HostConfig.Memory)
+
+	batch, err := inspector.ContainerInspect(context.Background(), "batch")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1024), batch.HostConfig.Memory)
+	assert.Equal(t, "ec2", batch.Config.Labels["com.newrelic.nri-docker.launch-type"])
+}
+
+func TestWithTaskLimits(t *testing.T) {
+	taskCPU := 1.0
+
+	for name, tc := range map[string]struct {
+		resources containerTypes.Resources
+		expected  int64
+	}{
+		"no container limit":         {resources: containerTypes.Resources{CPUShares: 512}, expected: 1e9},
+		"lower container limit":      {resources: containerTypes.Resources{NanoCPUs: 5e8}, expected: 5e8},
+		"higher container limit":     {resources: containerTypes.Resources{NanoCPUs: 2e9}, expected: 1e9},
+		"container quota is honored": {resources: containerTypes.Resources{CPUQuota: 50000}, expected: 0},
+	} {
+		t.Run(name, func(t *testing.T) {
+			withTaskLimits(&tc.resources, &LimitsResponse{CPU: &taskCPU})
+			assert.Equal(t, tc.expected, tc.resources.NanoCPUs)
+		})
+	}
+}
+
+func TestContainerEnv(t *testing.T) {
+	env := []string{"A=1", "ECS_CONTAINER_METADATA_URI_V4=http://169.254.170.2/v4/id", "B="}
+	assert.Equal(t, "http://169.254.170.2/v4/id", containerEnv(env, containerMetadataEnvVarV4))
+	assert.Equal(t, "", containerEnv(env, "B"))
+	assert.Equal(t, "", containerEnv(env, "C"))
+}
@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -22,7 +23,15 @@ var fargateHTTPClient = &http.Client{Timeout: fargateClientTimeout}
 type timedDockerStats struct {
 	//StatsJSON inherits all the fields from docker.Stats adding Network info
 	container.StatsResponse
-	time time.Time
+	// NetworkRateStats is only reported by the task metadata endpoint v4.
+	NetworkRateStats *NetworkRateStats `json:"network_rate_stats,omitempty"`
+	time             time.Time
+}
+
+// NetworkRateStats holds the network rates of a container computed by the ECS agent.
+type NetworkRateStats struct {
+	RxBytesPerSecond float64 `json:"rx_bytes_per_sec"`
+	TxBytesPerSecond float64 `json:"tx_bytes_per_sec"`
 }
 
 // FargateStats holds a map of Fargate container IDs as key and their Docker metrics
@@ -32,7 +41,7 @@ type FargateStats map[string]*timedDockerStats
 // FargateFetcher fetches metrics from Fargate endpoints in AWS ECS.
 type FargateFetcher struct {
 	baseURL        *url.URL
-	http           *http.Client
+	metadata       *metadataClient
 	containerStore persist.Storer
 	latestFetch    time.Time
 }
@@ -43,14 +52,14 @@ func NewFargateFetcher(baseURL *url.URL) (*FargateFetcher, error) {
 
 	return &FargateFetcher{
 		baseURL:        baseURL,
-		http:           fargateHTTPClient,
+		metadata:       sharedMetadataClient,
 		containerStore: containerStore,
 	}, nil
 }
 
 // Fetch fetches raw metrics from a given Fargate container.
-func (e *FargateFetcher) Fetch(container container.InspectResponse) (raw.Metrics, error) {
-	stats, err := e.fargateStatsFromCacheOrNew()
+func (e *FargateFetcher) Fetch(ctx context.Context, container container.InspectResponse) (raw.Metrics, error) {
+	stats, err := e.fargateStatsFromCacheOrNew(ctx)
 	if err != nil {
 		return raw.Metrics{}, err
 	}
@@ -58,11 +67,17 @@ func (e *FargateFetcher) Fetch(container container.InspectResponse) (raw.Metrics
 	if rawMetrics[container.ID] == nil {
 		return raw.Metrics{}, fmt.Errorf("the raw metric map did nont contain the container with ID: %s, %d", container.ID, len(rawMetrics))
 	}
-	return *rawMetrics[container.ID], nil
+	metrics := *rawMetrics[container.ID]
+	// the stats report the host memory as limit for the containers without a memory limit, which are limited by
+	// the task memory instead
+	if hc := container.HostConfig; hc != nil && hc.Memory > 0 && (metrics.Memory.UsageLimit == 0 || metrics.Memory.UsageLimit > uint64(hc.Memory)) {
+		metrics.Memory.UsageLimit = uint64(hc.Memory)
+	}
+	return metrics, nil
 }
 
 // fargateStatsFromCacheOrNew wraps the access to Fargate task stats with a caching layer.
-func (e *FargateFetcher) fargateStatsFromCacheOrNew() (FargateStats, error) {
+func (e *FargateFetcher) fargateStatsFromCacheOrNew(ctx context.Context) (FargateStats, error) {
 	defer func() {
 		if err := e.containerStore.Save(); err != nil {
 			log.Warn("error persisting Fargate task metadata: %s", err)
@@ -70,14 +85,13 @@ func (e *FargateFetcher) fargateStatsFromCacheOrNew() (FargateStats, error) {
 	}()
 
 	var response FargateStats
-	_, err := e.containerStore.Get(fargateTaskStatsCacheKey, &response)
-	if err == persist.ErrNotFound {
-		response, err = e.getFargateContainerMetrics()
-	}
+	err := cachedOrFetch(e.containerStore, fargateTaskStatsCacheKey, &response, func() (err error) {
+		response, err = e.getFargateContainerMetrics(ctx)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("cannot fetch task stats response: %s", err)
 	}
-	e.containerStore.Set(fargateTaskMetadataCacheKey, response)
 	return response, nil
 }
 
@@ -87,10 +101,10 @@ func (e *FargateFetcher) fargateStatsFromCacheOrNew() (FargateStats, error) {
 // Note that the endpoint doesn't follow strictly the same schema as Docker's: it returns a list of containers,
 // instead of only one. They are not compatible in terms of the requests that they accept, but they share
 // part of the response's schema.
-func (e *FargateFetcher) getFargateContainerMetrics() (FargateStats, error) {
+func (e *FargateFetcher) getFargateContainerMetrics(ctx context.Context) (FargateStats, error) {
 	endpoint := TaskStatsEndpoint(e.baseURL.String())
 
-	response, err := metadataResponse(e.http, endpoint)
+	response, err := e.metadata.get(ctx, endpoint)
 	if err != nil {
 		return nil, fmt.Errorf(
 			"error when sending request to ECS container metadata endpoint (%s): %v",
@@ -5,23 +5,62 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
+	"net/netip"
 	"net/url"
 	"strings"
+	"time"
 
 	containerTypes "github.com/moby/moby/api/types/container"
 	"github.com/newrelic/infra-integrations-sdk/v3/log"
 	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+
+	"github.com/newrelic/nri-docker/src/raw"
+)
+
+const (
+	fargateTaskMetadataCacheKey = "task-metadata-response"
+
+	// minCPUShares is the CPU shares value set to the containers without CPU units.
+	minCPUShares = 2
+	mebibyte     = 1024 * 1024
+
+	// desiredStatusLabel is a synthetic label with the status the ECS agent wants the container to reach
+	desiredStatusLabel = "com.newrelic.nri-docker.desired-status"
+
+	// synthetic labels with the ECS info of the container and its task
+	accountIDLabel    = "com.newrelic.nri-docker.account-id"
+	containerARNLabel = "com.newrelic.nri-docker.container-arn"
+	logGroupLabel     = "com.newrelic.nri-docker.log-group"
+	logStreamLabel    = "com.newrelic.nri-docker.log-stream"
+	// TagLabelPrefix prefixes the synthetic labels with the task and container instance tags.
+	TagLabelPrefix = "com.newrelic.nri-docker.tag."
+
+	awsLogsGroupOption  = "awslogs-group"
+	awsLogsStreamOption = "awslogs-stream"
+
+	// synthetic labels with the container network details
+	networkModeLabel    = "com.newrelic.nri-docker.network-mode"
+	ipv4AddressLabel    = "com.newrelic.nri-docker.ipv4-address"
+	ipv6AddressLabel    = "com.newrelic.nri-docker.ipv6-address"
+	ipv4SubnetCIDRLabel = "com.newrelic.nri-docker.ipv4-subnet-cidr-block"
+	ipv6SubnetCIDRLabel = "com.newrelic.nri-docker.ipv6-subnet-cidr-block"
+	macAddressLabel     = "com.newrelic.nri-docker.mac-address"
+	privateDNSNameLabel = "com.newrelic.nri-docker.private-dns-name"
 )
 
-const fargateTaskMetadataCacheKey = "task-metadata-response"
+var zeroTime time.Time
+
+// TaskMetadataClient defines how to access the metadata of the ECS task the integration runs in.
+type TaskMetadataClient interface {
+	TaskMetadata(ctx context.Context) (TaskResponse, error)
+}
 
 // FargateInspector is responsible for listing containers and inspecting containers in Fargate.
 // Both operations use the same data source and thus access it through a caching layer to avoid extra
 // computations.
 type FargateInspector struct {
 	baseURL        *url.URL
-	http           *http.Client
+	metadata       *metadataClient
 	containerStore persist.Storer
 }
 
@@ -31,52 +70,68 @@ func NewFargateInspector(baseURL *url.URL) (*FargateInspector, error) {
 
 	return &FargateInspector{
 		baseURL:        baseURL,
-		http:           fargateHTTPClient,
+		metadata:       sharedMetadataClient,
 		containerStore: containerStore,
 	}, nil
 }
 
 // ContainerList lists containers that the current Fargate container can see (only the container in the same
 // task). It completely ignores any listing option for the moment.
-func (i *FargateInspector) ContainerList(_ context.Context, _ bool) ([]containerTypes.Summary, error) {
+func (i *FargateInspector) ContainerList(ctx context.Context, _ bool) ([]containerTypes.Summary, error) {
 	var taskResponse TaskResponse
-	err := i.taskResponseFromCacheOrNew(&taskResponse)
+	err := i.taskResponseFromCacheOrNew(ctx, &taskResponse)
 	if err != nil {
 		return nil, err
 	}
 
 	containers := make([]containerTypes.Summary, len(taskResponse.Containers))
 	for index, container := range taskResponse.Containers {
-		converted := containerResponseToDocker(container)
+		converted := containerResponseToDocker(container, taskResponse)
 		containers[index] = converted
 	}
 	return containers, nil
 }
 
+// TaskMetadata returns the metadata of the task the current Fargate container belongs to.
+func (i *FargateInspector) TaskMetadata(ctx context.Context) (TaskResponse, error) {
+	var taskResponse TaskResponse
+	err := i.taskResponseFromCacheOrNew(ctx, &taskResponse)
+	return taskResponse, err
+}
+
 // taskResponseFromCacheOrNew wraps the access to Fargate task metadata with a caching layer.
-func (i *FargateInspector) taskResponseFromCacheOrNew(response *TaskResponse) error {
+func (i *FargateInspector) taskResponseFromCacheOrNew(ctx context.Context, response *TaskResponse) error {
 	defer func() {
 		if err := i.containerStore.Save(); err != nil {
 			log.Warn("error persisting Fargate task metadata: %s", err)
 		}
 	}()
 
-	var err error
-	_, err = i.containerStore.Get(fargateTaskMetadataCacheKey, response)
-	if err == persist.ErrNotFound {
-		err = i.fetchTaskResponse(response)
-	}
+	err := cachedOrFetch(i.containerStore, fargateTaskMetadataCacheKey, response, func() error {
+		return i.fetchTaskResponse(ctx, response)
+	})
 	if err != nil {
 		return fmt.Errorf("cannot fetch Fargate task metadata response: %s", err)
 	}
-	i.containerStore.Set(fargateTaskMetadataCacheKey, response)
 	return nil
 }
 
-func (i *FargateInspector) fetchTaskResponse(taskResponse *TaskResponse) error {
-	endpoint := TaskMetadataEndpoint(i.baseURL.String())
+func (i *FargateInspector) fetchTaskResponse(ctx context.Context, taskResponse *TaskResponse) error {
+	// the tags are only reported by the endpoint v4, so the task endpoint is queried if they cannot be fetched
+	endpoint := TaskWithTagsEndpoint(i.baseURL.String())
+	response, err := i.metadata.getOnce(ctx, endpoint)
+	if err == nil {
+		if err = json.Unmarshal(response, taskResponse); err == nil {
+			log.Debug("task metadata response from endpoint %s: %s", endpoint, string(response))
+			return nil
+		}
+	}
+	log.Debug("fetching task metadata with tags from %s, using the task endpoint instead: %v", endpoint, err)
+	*taskResponse = TaskResponse{}
+
+	endpoint = TaskMetadataEndpoint(i.baseURL.String())
 
-	response, err := metadataResponse(i.http, endpoint)
+	response, err = i.metadata.get(ctx, endpoint)
 	if err != nil {
 		return fmt.Errorf(
 			"error when sending request to ECS task metadata endpoint (%s): %v",
@@ -94,32 +149,99 @@ func (i *FargateInspector) fetchTaskResponse(taskResponse *TaskResponse) error {
 }
 
 // ContainerInspect returns metadata about a container given its container ID.
-func (i *FargateInspector) ContainerInspect(_ context.Context, containerID string) (containerTypes.InspectResponse, error) {
+func (i *FargateInspector) ContainerInspect(ctx context.Context, containerID string) (containerTypes.InspectResponse, error) {
 	var taskResponse TaskResponse
-	err := i.taskResponseFromCacheOrNew(&taskResponse)
+	err := i.taskResponseFromCacheOrNew(ctx, &taskResponse)
 	if err != nil {
 		return containerTypes.InspectResponse{}, err
 	}
 
 	for _, container := range taskResponse.Containers {
 		if container.ID == containerID {
-			containerJSON := containerTypes.InspectResponse{
-				ID: containerID,
-			}
-			return containerJSON, nil
+			return containerResponseToInspect(container, taskResponse), nil
 		}
 	}
 	return containerTypes.InspectResponse{}, errors.New("container not found")
 }
 
-func containerResponseToDocker(container ContainerResponse) containerTypes.Summary {
+// containerResponseToInspect synthesises the inspect response of a container from its metadata, so the limits,
+// state and labels are available as for the containers of a Docker daemon.
+func containerResponseToInspect(container ContainerResponse, task TaskResponse) containerTypes.InspectResponse {
+	inspect := containerTypes.InspectResponse{
+		ID:           container.ID,
+		Name:         "/" + container.DockerName,
+		Image:        container.ImageID,
+		RestartCount: container.RestartCount,
+		HostConfig:   &containerTypes.HostConfig{Resources: containerResources(container.Limits, task.Limits)},
+		State:        containerState(container),
+		Config: &containerTypes.Config{
+			Image:  container.Image,
+			Labels: fargateLabels(container, task),
+		},
+	}
+	if created := container.CreatedAt; created != nil {
+		inspect.Created = created.Format(time.RFC3339Nano)
+	}
+	return inspect
+}
+
+// containerResources returns the CPU and memory limits of the container, falling back to the task ones for the
+// limits which are not set at container level.
+func containerResources(limits LimitsResponse, taskLimits *LimitsResponse) containerTypes.Resources {
+	resources := containerTypes.Resources{}
+
+	// container CPU is set in CPU units and only applied as CPU shares, i.e. a relative weight and not a limit
+	if limits.CPU != nil && *limits.CPU > minCPUShares {
+		resources.CPUShares = int64(*limits.CPU)
+	}
+
+	// memory is set in MiB
+	if limits.Memory != nil && *limits.Memory > 0 {
+		resources.Memory = *limits.Memory * mebibyte
+	}
+
+	withTaskLimits(&resources, taskLimits)
+	return resources
+}
+
+// containerState maps the known status of the container to the Docker one.
+func containerState(container ContainerResponse) *containerTypes.State {
+	state := &containerTypes.State{
+		// as in Docker, unset times are reported as the zero time
+		StartedAt:  zeroTime.Format(time.RFC3339Nano),
+		FinishedAt: zeroTime.Format(time.RFC3339Nano),
+	}
+	switch strings.ToUpper(container.KnownStatus) {
+	case "RUNNING":
+		state.Status = containerTypes.StateRunning
+		state.Running = true
+	case "STOPPED":
+		state.Status = containerTypes.StateExited
+	default:
+		state.Status = containerTypes.StateCreated
+	}
+	if container.ExitCode != nil {
+		state.ExitCode = *container.ExitCode
+	}
+	if container.StartedAt != nil {
+		state.StartedAt = container.StartedAt.Format(time.RFC3339Nano)
+	}
+	if container.FinishedAt != nil {
+		state.FinishedAt = container.FinishedAt.Format(time.RFC3339Nano)
+	}
+	return state
+}
+
+func containerResponseToDocker(container ContainerResponse, task TaskResponse) containerTypes.Summary {
 	c := containerTypes.Summary{
 		ID:      container.ID,
 		Names:   []string{container.Name},
 		Image:   container.Image,
 		ImageID: container.ImageID,
-		Labels:  processFargateLabels(container.Labels),
+		Labels:  fargateLabels(container, task),
+		State:   containerState(container).Status,
 		Status:  container.KnownStatus,
+		Ports:   portResponsesToDocker(container.Ports),
 	}
 	if created := container.CreatedAt; created != nil {
 		c.Created = created.Unix()
@@ -127,7 +249,101 @@ func containerResponseToDocker(container ContainerResponse) containerTypes.Summa
 	return c
 }
 
+func portResponsesToDocker(ports []PortResponse) []containerTypes.PortSummary {
+	if len(ports) == 0 {
+		return nil
+	}
+	summaries := make([]containerTypes.PortSummary, 0, len(ports))
+	for _, p := range ports {
+		summary := containerTypes.PortSummary{
+			PrivatePort: p.ContainerPort,
+			PublicPort:  p.HostPort,
+			Type:        strings.ToLower(p.Protocol),
+		}
+		if summary.Type == "" {
+			summary.Type = "tcp"
+		}
+		if ip, err := netip.ParseAddr(p.HostIP); err == nil {
+			summary.IP = ip
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// fargateLabels returns the container labels, plus synthetic labels with the Fargate info of the container and
+// its task.
+func fargateLabels(container ContainerResponse, task TaskResponse) map[string]string {
+	labels := processFargateLabels(container.Labels)
+	addNetworkLabels(labels, container.Networks)
+	addTagLabels(labels, task)
+
+	launchType := raw.FargateLaunchType
+	if task.LaunchType != "" {
+		// the task metadata endpoint is also available to the tasks of the EC2 launch type
+		launchType = strings.ToLower(task.LaunchType)
+	}
+	for label, value := range map[string]string{
+		raw.LaunchTypeLabel: launchType,
+		desiredStatusLabel:  container.DesiredStatus,
+		containerARNLabel:   container.ContainerARN,
+		serviceNameLabel:    task.ServiceName,
+		logGroupLabel:       container.LogOptions[awsLogsGroupOption],
+		logStreamLabel:      container.LogOptions[awsLogsStreamOption],
+	} {
+		if value != "" {
+			labels[label] = value
+		}
+	}
+	return labels
+}
+
+// addTagLabels adds a synthetic label for each of the container instance and task tags. Task tags take precedence
+// over the container instance ones with the same key.
+func addTagLabels(labels map[string]string, task TaskResponse) {
+	for _, tags := range []map[string]string{task.ContainerInstanceTags, task.TaskTags} {
+		for key, value := range tags {
+			labels[TagLabelPrefix+key] = value
+		}
+	}
+}
+
+// addNetworkLabels adds synthetic labels with the details of the container network. Tasks using the awsvpc network
+// mode have a single network interface, so only the first network is considered.
+func addNetworkLabels(labels map[string]string, networks []Network) {
+	if len(networks) == 0 {
+		return
+	}
+	if len(networks) > 1 {
+		log.Debug("container has %d networks, only the first one is reported", len(networks))
+	}
+
+	n := networks[0]
+	for label, value := range map[string]string{
+		networkModeLabel:    n.NetworkMode,
+		ipv4AddressLabel:    strings.Join(n.IPv4Addresses, ","),
+		ipv6AddressLabel:    strings.Join(n.IPv6Addresses, ","),
+		ipv4SubnetCIDRLabel: n.IPv4SubnetCIDRBlock,
+		ipv6SubnetCIDRLabel: n.IPv6SubnetCIDRBlock,
+		macAddressLabel:     n.MACAddress,
+		privateDNSNameLabel: n.PrivateDNSName,
+	} {
+		if value != "" {
+			labels[label] = value
+		}
+	}
+}
+
 func processFargateLabels(labels map[string]string) map[string]string {
+	return processECSLabels(labels, raw.FargateLaunchType)
+}
+
+// processECSLabels adds synthetic labels with the cluster ARN, the region and the given launch type to the labels
+// set by the ECS agent.
+func processECSLabels(labels map[string]string, launchType string) map[string]string {
+	if labels == nil {
+		labels = map[string]string{}
+	}
 	for label, value := range labels {
 		switch label {
 		case "com.amazonaws.ecs.cluster":
@@ -140,22 +356,50 @@ func processFargateLabels(labels map[string]string) map[string]string {
 			}
 
 		case "com.amazonaws.ecs.task-arn":
-			// Obtain aws region from task arn
-			if region := regionFromTaskARN(value); region != "" {
-				labels["com.newrelic.nri-docker.aws-region"] = region
-			} else {
+			// Obtain aws region and account from task arn
+			a, err := parseARN(value)
+			if err != nil {
 				// Log an error if task-arn is not an arn
 				log.Error("could not process task arn: %s", label)
+				continue
+			}
+			if a.region != "" {
+				labels["com.newrelic.nri-docker.aws-region"] = a.region
+			}
+			if a.accountID != "" {
+				labels[accountIDLabel] = a.accountID
 			}
 		}
 	}
 
-	// Add label signaling fargate launch type
-	labels["com.newrelic.nri-docker.launch-type"] = "fargate"
+	// Add label signaling the launch type
+	labels[raw.LaunchTypeLabel] = launchType
 
 	return labels
 }
 
+// ClusterName returns the name of the task cluster, which is reported as an ARN in Fargate.
+func (t TaskResponse) ClusterName() string {
+	if name := clusterNameFromARN(t.Cluster); name != "" {
+		return name
+	}
+	return t.Cluster
+}
+
+// Region returns the AWS region of the task, taken from its ARN.
+func (t TaskResponse) Region() string {
+	return regionFromTaskARN(t.TaskARN)
+}
+
+// AccountID returns the ID of the AWS account owning the task, taken from its ARN.
+func (t TaskResponse) AccountID() string {
+	a, err := parseARN(t.TaskARN)
+	if err != nil {
+		return ""
+	}
+	return a.accountID
+}
+
 // clusterNameFromARN extracts the cluster name from an ECS cluster ARN.
 func clusterNameFromARN(ecsClusterARN string) string {
 	a, err := parseARN(ecsClusterARN)
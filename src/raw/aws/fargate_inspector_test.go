@@ -1,6 +1,13 @@
 package aws
 
-import "testing"
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	containerTypes "github.com/moby/moby/api/types/container"
+	"github.com/stretchr/testify/assert"
+)
 
 func TestProcessFargateLabels(t *testing.T) {
 	labels := processFargateLabels(map[string]string{
@@ -15,6 +22,7 @@ func TestProcessFargateLabels(t *testing.T) {
 		"com.amazonaws.ecs.task-arn":          "arn:aws:ecs:my-region:100000000000:task/the-cluster-name/f05a5672397746638bc201a252c5bb75",
 		"com.newrelic.nri-docker.cluster-arn": "arn:aws:ecs:my-region:100000000000:cluster/the-cluster-name",
 		"com.newrelic.nri-docker.aws-region":  "my-region",
+		"com.newrelic.nri-docker.account-id":  "100000000000",
 		"com.newrelic.nri-docker.launch-type": "fargate", // Set to fargate as 'com.amazonaws.ecs.cluster' is an arn
 	}
 
@@ -24,3 +32,130 @@ func TestProcessFargateLabels(t *testing.T) {
 		}
 	}
 }
+
+func TestPortResponsesToDocker(t *testing.T) {
+	ports := portResponsesToDocker([]PortResponse{
+		{ContainerPort: 80, Protocol: "tcp", HostPort: 80},
+		{ContainerPort: 53, Protocol: "UDP", HostPort: 5353, HostIP: "0.0.0.0"},
+		{ContainerPort: 8080},
+	})
+	assert.Equal(t, []containerTypes.PortSummary{
+		{PrivatePort: 80, PublicPort: 80, Type: "tcp"},
+		{PrivatePort: 53, PublicPort: 5353, Type: "udp", IP: netip.MustParseAddr("0.0.0.0")},
+		{PrivatePort: 8080, Type: "tcp"},
+	}, ports)
+	assert.Nil(t, portResponsesToDocker(nil))
+}
+
+func TestContainerResponseToInspect(t *testing.T) {
+	cpuUnits, memoryMiB := 512.0, int64(256)
+	taskCPU, taskMemoryMiB := 2.0, int64(4096)
+	noCPU, noMemory := 0.0, int64(0)
+	exitCode := 137
+	started := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	finished := started.Add(time.Hour)
+
+	inspect := containerResponseToInspect(ContainerResponse{
+		ID:           "app",
+		DockerName:   "ecs-api-7-app",
+		Image:        "nginx:latest",
+		ImageID:      "sha256:abc",
+		KnownStatus:  "STOPPED",
+		ExitCode:     &exitCode,
+		StartedAt:    &started,
+		FinishedAt:   &finished,
+		RestartCount: 2,
+		Limits:       LimitsResponse{CPU: &cpuUnits, Memory: &memoryMiB},
+		Labels:       map[string]string{"com.amazonaws.ecs.container-name": "app"},
+	}, TaskResponse{Limits: &LimitsResponse{CPU: &taskCPU, Memory: &taskMemoryMiB}})
+
+	assert.Equal(t, "app", inspect.ID)
+	assert.Equal(t, "/ecs-api-7-app", inspect.Name)
+	assert.Equal(t, 2, inspect.RestartCount)
+	assert.Equal(t, int64(512), inspect.HostConfig.CPUShares)
+	assert.Equal(t, int64(2e9), inspect.HostConfig.NanoCPUs, "the task CPU is the hard limit")
+	assert.Equal(t, int64(256*mebibyte), inspect.HostConfig.Memory)
+	assert.Equal(t, containerTypes.StateExited, inspect.State.Status)
+	assert.False(t, inspect.State.Running)
+	assert.Equal(t, 137, inspect.State.ExitCode)
+	assert.Equal(t, "2026-01-01T10:00:00Z", inspect.State.StartedAt)
+	assert.Equal(t, "2026-01-01T11:00:00Z", inspect.State.FinishedAt)
+	assert.Equal(t, "nginx:latest", inspect.Config.Image)
+	assert.Equal(t, "app", inspect.Config.Labels["com.amazonaws.ecs.container-name"])
+	assert.Equal(t, "fargate", inspect.Config.Labels["com.newrelic.nri-docker.launch-type"])
+
+	t.Run("task limits are used if the container has none", func(t *testing.T) {
+		inspect := containerResponseToInspect(ContainerResponse{
+			ID:          "sidecar",
+			KnownStatus: "RUNNING",
+			Limits:      LimitsResponse{CPU: &noCPU, Memory: &noMemory},
+		}, TaskResponse{Limits: &LimitsResponse{CPU: &taskCPU, Memory: &taskMemoryMiB}})
+
+		assert.Equal(t, int64(2e9), inspect.HostConfig.NanoCPUs)
+		assert.Equal(t, int64(4096*mebibyte), inspect.HostConfig.Memory)
+		assert.Equal(t, containerTypes.StateRunning, inspect.State.Status)
+		assert.True(t, inspect.State.Running)
+		assert.Equal(t, "0001-01-01T00:00:00Z", inspect.State.FinishedAt)
+	})
+}
+
+func TestFargateNetworkLabels(t *testing.T) {
+	labels := fargateLabels(ContainerResponse{
+		Labels: map[string]string{"com.amazonaws.ecs.container-name": "app"},
+		Networks: []Network{{
+			NetworkMode:         "awsvpc",
+			IPv4Addresses:       []string{"10.0.2.61"},
+			IPv4SubnetCIDRBlock: "10.0.2.0/24",
+			MACAddress:          "0e:10:e2:01:bd:91",
+			PrivateDNSName:      "ip-10-0-2-61.us-west-2.compute.internal",
+		}},
+	}, TaskResponse{})
+
+	assert.Equal(t, map[string]string{
+		"com.amazonaws.ecs.container-name":               "app",
+		"com.newrelic.nri-docker.launch-type":            "fargate",
+		"com.newrelic.nri-docker.network-mode":           "awsvpc",
+		"com.newrelic.nri-docker.ipv4-address":           "10.0.2.61",
+		"com.newrelic.nri-docker.ipv4-subnet-cidr-block": "10.0.2.0/24",
+		"com.newrelic.nri-docker.mac-address":            "0e:10:e2:01:bd:91",
+		"com.newrelic.nri-docker.private-dns-name":       "ip-10-0-2-61.us-west-2.compute.internal",
+	}, labels)
+}
+
+func TestFargateTaskLabels(t *testing.T) {
+	task := TaskResponse{
+		TaskARN:               "arn:aws:ecs:us-west-2:111122223333:task/default/158d1c8083dd49d6b527399fd6414f5c",
+		LaunchType:            "FARGATE",
+		ServiceName:           "api",
+		TaskTags:              map[string]string{"team": "payments", "env": "prod"},
+		ContainerInstanceTags: map[string]string{"env": "staging", "os": "linux"},
+	}
+	labels := fargateLabels(ContainerResponse{
+		ContainerARN: "arn:aws:ecs:us-west-2:111122223333:container/abb51bdd-11b4-467f-8f6c-adcfe1fe059d",
+		Labels:       map[string]string{"com.amazonaws.ecs.task-arn": task.TaskARN},
+		LogDriver:    "awslogs",
+		LogOptions: map[string]string{
+			"awslogs-group":  "/ecs/api",
+			"awslogs-region": "us-west-2",
+			"awslogs-stream": "ecs/app/158d1c8083dd49d6b527399fd6414f5c",
+		},
+	}, task)
+
+	assert.Equal(t, map[string]string{
+		"com.amazonaws.ecs.task-arn":            task.TaskARN,
+		"com.newrelic.nri-docker.aws-region":    "us-west-2",
+		"com.newrelic.nri-docker.account-id":    "111122223333",
+		"com.newrelic.nri-docker.launch-type":   "fargate",
+		"com.newrelic.nri-docker.container-arn": "arn:aws:ecs:us-west-2:111122223333:container/abb51bdd-11b4-467f-8f6c-adcfe1fe059d",
+		"com.newrelic.nri-docker.service-name":  "api",
+		"com.newrelic.nri-docker.log-group":     "/ecs/api",
+		"com.newrelic.nri-docker.log-stream":    "ecs/app/158d1c8083dd49d6b527399fd6414f5c",
+		"com.newrelic.nri-docker.tag.team":      "payments",
+		"com.newrelic.nri-docker.tag.env":       "prod",
+		"com.newrelic.nri-docker.tag.os":        "linux",
+	}, labels)
+
+	task.LaunchType = "EC2"
+	assert.Equal(t, "ec2", fargateLabels(ContainerResponse{}, task)["com.newrelic.nri-docker.launch-type"])
+	assert.Equal(t, "111122223333", task.AccountID())
+}
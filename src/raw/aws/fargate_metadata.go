@@ -15,9 +15,6 @@ package aws
 
 import (
 	"errors"
-	"fmt"
-	"io/ioutil"
-	"net/http"
 	"net/url"
 	"os"
 	"time"
@@ -28,8 +25,6 @@ import (
 const (
 	containerMetadataEnvVar   = "ECS_CONTAINER_METADATA_URI"
 	containerMetadataEnvVarV4 = "ECS_CONTAINER_METADATA_URI_V4"
-	maxRetries                = 4
-	durationBetweenRetries    = time.Second
 )
 
 var errMetadata = errors.New("could not parse any Metadata API URL")
@@ -43,17 +38,25 @@ type TaskResponse struct {
 	DesiredStatus      string              `json:"DesiredStatus,omitempty"`
 	KnownStatus        string              `json:"KnownStatus"`
 	AvailabilityZone   string              `json:"AvailabilityZone"`
+	LaunchType         string              `json:"LaunchType,omitempty"`
+	ServiceName        string              `json:"ServiceName,omitempty"`
 	Containers         []ContainerResponse `json:"Containers,omitempty"`
 	Limits             *LimitsResponse     `json:"Limits,omitempty"`
 	PullStartedAt      *time.Time          `json:"PullStartedAt,omitempty"`
 	PullStoppedAt      *time.Time          `json:"PullStoppedAt,omitempty"`
 	ExecutionStoppedAt *time.Time          `json:"ExecutionStoppedAt,omitempty"`
+	// EphemeralStorageMetrics is only reported by the task metadata endpoint v4 of Fargate tasks.
+	EphemeralStorageMetrics *EphemeralStorageMetrics `json:"EphemeralStorageMetrics,omitempty"`
+	// TaskTags and ContainerInstanceTags are only reported by the taskWithTags endpoint v4.
+	TaskTags              map[string]string `json:"TaskTags,omitempty"`
+	ContainerInstanceTags map[string]string `json:"ContainerInstanceTags,omitempty"`
 }
 
 // ContainerResponse defines the schema for the container response
 // JSON object
 type ContainerResponse struct {
 	ID            string            `json:"DockerId"`
+	ContainerARN  string            `json:"ContainerARN,omitempty"`
 	Name          string            `json:"Name"`
 	DockerName    string            `json:"DockerName"`
 	Image         string            `json:"Image"`
@@ -70,6 +73,9 @@ type ContainerResponse struct {
 	Type          string            `json:"Type"`
 	Networks      []Network         `json:"Networks,omitempty"`
 	Health        HealthStatus      `json:"Health,omitempty"`
+	RestartCount  int               `json:"RestartCount,omitempty"`
+	LogDriver     string            `json:"LogDriver,omitempty"`
+	LogOptions    map[string]string `json:"LogOptions,omitempty"`
 }
 
 // LimitsResponse defines the schema for task/cpu limits response
@@ -79,6 +85,13 @@ type LimitsResponse struct {
 	Memory *int64   `json:"Memory,omitempty"`
 }
 
+// EphemeralStorageMetrics defines the schema for the task ephemeral storage
+// JSON object. Sizes are in MiB.
+type EphemeralStorageMetrics struct {
+	Utilized int64 `json:"Utilized"`
+	Reserved int64 `json:"Reserved"`
+}
+
 // HealthStatus represents a container's health.
 type HealthStatus struct {
 	Status   string     `json:"status,omitempty"`
@@ -93,6 +106,7 @@ type PortResponse struct {
 	ContainerPort uint16 `json:"ContainerPort,omitempty"`
 	Protocol      string `json:"Protocol,omitempty"`
 	HostPort      uint16 `json:"HostPort,omitempty"`
+	HostIP        string `json:"HostIp,omitempty"`
 }
 
 // Network is a struct that keeps track of metadata of a network interface
@@ -100,42 +114,11 @@ type Network struct {
 	NetworkMode   string   `json:"NetworkMode,omitempty"`
 	IPv4Addresses []string `json:"IPv4Addresses,omitempty"`
 	IPv6Addresses []string `json:"IPv6Addresses,omitempty"`
-}
-
-// metadataResponse gets the response from the given endpoint using the given HTTP client.
-func metadataResponse(client *http.Client, endpoint string) ([]byte, error) {
-	var resp []byte
-	var err error
-	for i := 0; i < maxRetries; i++ {
-		resp, err = sendMetadataRequest(client, endpoint)
-		if err == nil {
-			return resp, nil
-		}
-		log.Warn("Attempt [%d/%d]: unable to get metadata response from '%s': %v",
-			i, maxRetries, endpoint, err)
-		time.Sleep(durationBetweenRetries)
-	}
-
-	return nil, err
-}
-
-func sendMetadataRequest(client *http.Client, endpoint string) ([]byte, error) {
-	resp, err := client.Get(endpoint)
-	if err != nil {
-		return nil, fmt.Errorf("unable to get response from %s: %v", endpoint, err)
-	}
-	if resp.Body != nil {
-		defer resp.Body.Close()
-	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("incorrect status code querying %s: %d", endpoint, resp.StatusCode)
-	}
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("unable to read response body from %s: %v", endpoint, err)
-	}
-
-	return body, nil
+	// The following fields are only reported by the task metadata endpoint v4 for awsvpc tasks.
+	IPv4SubnetCIDRBlock string `json:"IPv4SubnetCIDRBlock,omitempty"`
+	IPv6SubnetCIDRBlock string `json:"IPv6SubnetCIDRBlock,omitempty"`
+	MACAddress          string `json:"MACAddress,omitempty"`
+	PrivateDNSName      string `json:"PrivateDNSName,omitempty"`
 }
 
 // TaskMetadataEndpoint returns the V3 endpoint to fetch task metadata given a base URL.
@@ -143,6 +126,12 @@ func TaskMetadataEndpoint(baseURL string) string {
 	return baseURL + "/task"
 }
 
+// TaskWithTagsEndpoint returns the V4 endpoint to fetch task metadata along with the task and container instance
+// tags given a base URL.
+func TaskWithTagsEndpoint(baseURL string) string {
+	return baseURL + "/taskWithTags"
+}
+
 // TaskStatsEndpoint returns the V3 endpoint to fetch task stats given a base URL.
 func TaskStatsEndpoint(baseURL string) string {
 	return baseURL + "/task/stats"
@@ -3,6 +3,8 @@ package aws
 import (
 	"time"
 
+	"github.com/moby/moby/api/types/container"
+
 	"github.com/newrelic/infra-integrations-sdk/v3/log"
 	"github.com/newrelic/nri-docker/src/raw"
 )
@@ -20,13 +22,8 @@ func fargateRawMetrics(fargateStats FargateStats) map[string]*raw.Metrics {
 		rawMetrics[containerID] = &raw.Metrics{
 			Time:        now,
 			ContainerID: containerID,
-			Memory: raw.Memory{
-				UsageLimit: stats.MemoryStats.Limit,
-				Cache:      stats.MemoryStats.Stats["cache"],
-				RSS:        stats.MemoryStats.Stats["rss"],
-				FuzzUsage:  0,
-			},
-			Network: network,
+			Memory:      fargateMemory(stats.MemoryStats),
+			Network:     network,
 			CPU: raw.CPU{
 				TotalUsage:        stats.CPUStats.CPUUsage.TotalUsage,
 				UsageInUsermode:   &stats.CPUStats.CPUUsage.UsageInUsermode,
@@ -35,6 +32,7 @@ func fargateRawMetrics(fargateStats FargateStats) map[string]*raw.Metrics {
 				ThrottledPeriods:  stats.CPUStats.ThrottlingData.ThrottledPeriods,
 				ThrottledTimeNS:   stats.CPUStats.ThrottlingData.ThrottledTime,
 				SystemUsage:       stats.CPUStats.SystemUsage,
+				OnlineCPUs:        uint(stats.CPUStats.OnlineCPUs),
 			},
 			Pids: raw.Pids{
 				Current: stats.PidsStats.Current,
@@ -63,6 +61,27 @@ func fargateRawMetrics(fargateStats FargateStats) map[string]*raw.Metrics {
 	return rawMetrics
 }
 
+// fargateMemory returns the memory metrics from the stats, whose keys depend on the cgroups version used by the
+// Fargate platform version. They are mapped as the Docker API fetcher does.
+func fargateMemory(stats container.MemoryStats) raw.Memory {
+	mem := raw.Memory{}
+	if stats.Usage != 0 {
+		mem.UsageLimit = stats.Limit
+		mem.FuzzUsage = stats.Usage
+	}
+
+	if _, ok := stats.Stats["anon"]; ok { // cgroups v2
+		mem.Cache = stats.Stats["file"]
+		mem.RSS = stats.Stats["anon"]
+		mem.KernelMemoryUsage = stats.Stats["kernel_stack"] + stats.Stats["slab"]
+		return mem
+	}
+
+	mem.Cache = stats.Stats["cache"]
+	mem.RSS = stats.Stats["rss"]
+	return mem
+}
+
 func computeNetworkStats(stats *timedDockerStats) raw.Network {
 	n := raw.Network{}
 	var RxBytes, RxDropped, RxErrors, RxPackets, TxBytes, TxDropped, TxErrors, TxPackets uint64
@@ -89,5 +108,9 @@ func computeNetworkStats(stats *timedDockerStats) raw.Network {
 			TxPackets: int64(TxPackets),
 		}
 	}
+	if rates := stats.NetworkRateStats; rates != nil {
+		n.RxBytesPerSecond = &rates.RxBytesPerSecond
+		n.TxBytesPerSecond = &rates.TxBytesPerSecond
+	}
 	return n
 }
@@ -0,0 +1,38 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/newrelic/nri-docker/src/raw"
+)
+
+func TestFargateMemory(t *testing.T) {
+	t.Run("cgroups v1", func(t *testing.T) {
+		mem := fargateMemory(container.MemoryStats{
+			Usage: 1000,
+			Limit: 4000,
+			Stats: map[string]uint64{"cache": 300, "rss": 600, "active_anon": 500},
+		})
+		assert.Equal(t, raw.Memory{UsageLimit: 4000, FuzzUsage: 1000, Cache: 300, RSS: 600}, mem)
+	})
+
+	t.Run("cgroups v2", func(t *testing.T) {
+		mem := fargateMemory(container.MemoryStats{
+			Usage: 1000,
+			Limit: 4000,
+			Stats: map[string]uint64{"file": 300, "anon": 600, "kernel_stack": 20, "slab": 30},
+		})
+		assert.Equal(t, raw.Memory{UsageLimit: 4000, FuzzUsage: 1000, Cache: 300, RSS: 600, KernelMemoryUsage: 50}, mem)
+	})
+}
+
+func TestFargateRawMetricsOnlineCPUs(t *testing.T) {
+	stats := &timedDockerStats{}
+	stats.CPUStats.OnlineCPUs = 2
+
+	metrics := fargateRawMetrics(FargateStats{"app": stats})
+	assert.Equal(t, uint(2), metrics["app"].CPU.OnlineCPUs)
+}
@@ -0,0 +1,238 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+)
+
+const (
+	maxRetries     = 4
+	initialBackoff = 250 * time.Millisecond
+	maxBackoff     = 4 * time.Second
+	// maxEndpointLength limits the length of the endpoint URLs, which are taken from the environment. The requests
+	// are GETs without a body, so the URL is the only part of their size depending on the input.
+	maxEndpointLength = 2048
+	// maxResponseBytes limits the size of the responses. The stats of a task with tens of containers are a few
+	// hundreds of KiB.
+	maxResponseBytes = 8 << 20
+	// metadataCacheTTL is the time the responses are reused for, so each endpoint is queried once per run.
+	metadataCacheTTL = 10 * time.Second
+)
+
+var errResponseTooLarge = errors.New("response too large")
+
+// sharedMetadataClient is the client used to query the ECS metadata and agent endpoints, so the errors of all the
+// endpoints are accounted together.
+var sharedMetadataClient = newMetadataClient(fargateHTTPClient)
+
+// metadataClient queries the ECS metadata endpoints, retrying the failed requests with exponential backoff and
+// jitter. Concurrent requests to the same endpoint are made once and share the response.
+type metadataClient struct {
+	http             *http.Client
+	initialBackoff   time.Duration
+	maxBackoff       time.Duration
+	maxResponseBytes int64
+
+	mu    sync.Mutex
+	calls map[string]*metadataCall
+	// errors counts the failed requests of each endpoint.
+	errors map[string]int
+}
+
+// metadataCall is an in-flight request to an endpoint.
+type metadataCall struct {
+	done chan struct{}
+	body []byte
+	err  error
+}
+
+func newMetadataClient(client *http.Client) *metadataClient {
+	return &metadataClient{
+		http:             client,
+		initialBackoff:   initialBackoff,
+		maxBackoff:       maxBackoff,
+		maxResponseBytes: maxResponseBytes,
+		calls:            map[string]*metadataCall{},
+		errors:           map[string]int{},
+	}
+}
+
+// get returns the body of the endpoint response, retrying the failed requests until the context is done.
+func (c *metadataClient) get(ctx context.Context, endpoint string) ([]byte, error) {
+	return c.do(ctx, endpoint, false)
+}
+
+// getOnce returns the body of the endpoint response without retrying, for optional endpoints which are expected
+// to fail, so their failures are logged at debug level and not counted as errors.
+func (c *metadataClient) getOnce(ctx context.Context, endpoint string) ([]byte, error) {
+	return c.do(ctx, endpoint, true)
+}
+
+func (c *metadataClient) do(ctx context.Context, endpoint string, optional bool) ([]byte, error) {
+	c.mu.Lock()
+	if call, ok := c.calls[endpoint]; ok {
+		c.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.body, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &metadataCall{done: make(chan struct{})}
+	c.calls[endpoint] = call
+	c.mu.Unlock()
+
+	call.body, call.err = c.fetch(ctx, endpoint, optional)
+
+	c.mu.Lock()
+	delete(c.calls, endpoint)
+	c.mu.Unlock()
+	close(call.done)
+
+	return call.body, call.err
+}
+
+func (c *metadataClient) fetch(ctx context.Context, endpoint string, optional bool) ([]byte, error) {
+	if len(endpoint) > maxEndpointLength {
+		return nil, fmt.Errorf("endpoint URL longer than %d characters", maxEndpointLength)
+	}
+
+	if optional {
+		body, _, err := c.send(ctx, endpoint)
+		if err != nil {
+			log.Debug("unable to get metadata response from optional endpoint '%s': %v", endpoint, err)
+		}
+		return body, err
+	}
+
+	attempts := maxRetries
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			timer := time.NewTimer(c.backoff(attempt - 1))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		var body []byte
+		var retryable bool
+		body, retryable, err = c.send(ctx, endpoint)
+		if err == nil {
+			return body, nil
+		}
+		log.Warn("Attempt [%d/%d]: unable to get metadata response from '%s' (%d errors): %v",
+			attempt, attempts, endpoint, c.countError(endpoint), err)
+		if !retryable || ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, err
+}
+
+// send makes a single request, returning whether it can be retried if it fails.
+func (c *metadataClient) send(ctx context.Context, endpoint string) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid endpoint %s: %v", endpoint, err)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, true, fmt.Errorf("unable to get response from %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// client errors but throttling are not solved by retrying
+		retryable := resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+		return nil, retryable, fmt.Errorf("incorrect status code querying %s: %d", endpoint, resp.StatusCode)
+	}
+	if resp.ContentLength > c.maxResponseBytes {
+		return nil, false, fmt.Errorf("reading response from %s: %w, the limit is %d bytes", endpoint, errResponseTooLarge, c.maxResponseBytes)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, c.maxResponseBytes+1))
+	if err != nil {
+		return nil, true, fmt.Errorf("unable to read response body from %s: %v", endpoint, err)
+	}
+	if int64(len(body)) > c.maxResponseBytes {
+		return nil, false, fmt.Errorf("reading response from %s: %w, the limit is %d bytes", endpoint, errResponseTooLarge, c.maxResponseBytes)
+	}
+	return body, false, nil
+}
+
+// backoff returns the time to wait before the given retry: an exponentially growing delay, up to the max backoff,
+// of which a random half is waited so the retries of several integrations are spread.
+func (c *metadataClient) backoff(retry int) time.Duration {
+	delay := c.maxBackoff
+	if shift := retry - 1; shift < 32 && c.initialBackoff<<shift < c.maxBackoff {
+		delay = c.initialBackoff << shift
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+func (c *metadataClient) countError(endpoint string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errors[endpoint]++
+	return c.errors[endpoint]
+}
+
+// errorCounts returns the number of failed requests of each endpoint.
+func (c *metadataClient) errorCounts() map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counts := make(map[string]int, len(c.errors))
+	for endpoint, count := range c.errors {
+		counts[endpoint] = count
+	}
+	return counts
+}
+
+// LogMetadataErrors logs the number of failed requests of each of the ECS endpoints queried in the run, if any.
+func LogMetadataErrors() {
+	counts := sharedMetadataClient.errorCounts()
+	endpoints := make([]string, 0, len(counts))
+	for endpoint := range counts {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+	for _, endpoint := range endpoints {
+		log.Warn("%d requests to the ECS endpoint %s failed", counts[endpoint], endpoint)
+	}
+}
+
+// cachedOrFetch gets the value of the key from the store into valuePtr, unless it is missing or older than the
+// metadata cache TTL, in which case it is fetched and stored again.
+func cachedOrFetch(store persist.Storer, key string, valuePtr interface{}, fetch func() error) error {
+	storedAt, err := store.Get(key, valuePtr)
+	if err == nil && time.Since(time.Unix(storedAt, 0)) < metadataCacheTTL {
+		return nil
+	}
+	if err != nil && !errors.Is(err, persist.ErrNotFound) {
+		return err
+	}
+	// the expired value is discarded, so it is not merged with the fetched one
+	reflect.ValueOf(valuePtr).Elem().SetZero()
+	if err := fetch(); err != nil {
+		return err
+	}
+	store.Set(key, valuePtr)
+	return nil
+}
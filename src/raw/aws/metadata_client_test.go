@@ -0,0 +1,169 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMetadataClient() *metadataClient {
+	c := newMetadataClient(&http.Client{Timeout: time.Second})
+	c.initialBackoff, c.maxBackoff = time.Millisecond, 4*time.Millisecond
+	return c
+}
+
+func TestMetadataClientRetries(t *testing.T) {
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	c := testMetadataClient()
+	body, err := c.get(context.Background(), ts.URL+"/task")
+	require.NoError(t, err)
+	assert.Equal(t, "{}", string(body))
+	assert.Equal(t, int32(3), requests.Load())
+	assert.Equal(t, map[string]int{ts.URL + "/task": 2}, c.errorCounts())
+}
+
+func TestMetadataClientErrors(t *testing.T) {
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/large":
+			_, _ = w.Write([]byte(strings.Repeat("a", 11)))
+		case "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c := testMetadataClient()
+	c.maxResponseBytes = 10
+
+	_, err := c.get(context.Background(), ts.URL+"/missing")
+	assert.ErrorContains(t, err, "404")
+	assert.Equal(t, int32(1), requests.Load(), "client errors are not retried")
+
+	_, err = c.get(context.Background(), ts.URL+"/large")
+	assert.ErrorIs(t, err, errResponseTooLarge)
+
+	_, err = c.get(context.Background(), ts.URL+"/"+strings.Repeat("a", maxEndpointLength))
+	assert.ErrorContains(t, err, "longer than")
+
+	requests.Store(0)
+	_, err = c.getOnce(context.Background(), ts.URL+"/unavailable")
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), requests.Load())
+	assert.NotContains(t, c.errorCounts(), ts.URL+"/unavailable", "optional endpoints failures are not counted")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.get(ctx, ts.URL+"/unavailable")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestMetadataClientSharesInFlightRequests(t *testing.T) {
+	var requests atomic.Int32
+	started, release := make(chan struct{}), make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	c := testMetadataClient()
+	var wg sync.WaitGroup
+	get := func() {
+		defer wg.Done()
+		body, err := c.get(context.Background(), ts.URL+"/task/stats")
+		assert.NoError(t, err)
+		assert.Equal(t, "{}", string(body))
+	}
+
+	wg.Add(1)
+	go get()
+	<-started
+	for n := 0; n < 4; n++ {
+		wg.Add(1)
+		go get()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestMetadataClientBackoff(t *testing.T) {
+	c := newMetadataClient(http.DefaultClient)
+	for retry, max := range map[int]time.Duration{1: 250 * time.Millisecond, 2: 500 * time.Millisecond, 5: 4 * time.Second, 40: 4 * time.Second} {
+		for n := 0; n < 10; n++ {
+			backoff := c.backoff(retry)
+			assert.GreaterOrEqual(t, backoff, max/2)
+			assert.LessOrEqual(t, backoff, max)
+		}
+	}
+}
+
+func TestFargateFetcherCachesStats(t *testing.T) {
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte(`{"app":{"memory_stats":{"usage":1}},"sidecar":{"memory_stats":{"usage":2}}}`))
+	}))
+	defer ts.Close()
+	baseURL, err := url.Parse(ts.URL + "/v4/task-id")
+	require.NoError(t, err)
+
+	fetcher, err := NewFargateFetcher(baseURL)
+	require.NoError(t, err)
+	fetcher.metadata = testMetadataClient()
+
+	for _, id := range []string{"app", "sidecar", "app"} {
+		_, err := fetcher.Fetch(context.Background(), container.InspectResponse{ID: id})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), requests.Load(), "the task stats are fetched once per run")
+}
+
+func TestCachedOrFetch(t *testing.T) {
+	store := persist.NewInMemoryStore()
+	fetches := 0
+	fetch := func(value *string) func() error {
+		return func() error {
+			fetches++
+			*value = "fetched"
+			return nil
+		}
+	}
+
+	for n := 0; n < 2; n++ {
+		var value string
+		require.NoError(t, cachedOrFetch(store, "key", &value, fetch(&value)))
+		assert.Equal(t, "fetched", value)
+	}
+	assert.Equal(t, 1, fetches)
+}
@@ -5,6 +5,7 @@ package raw
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -43,7 +44,7 @@ func NewCgroupsV1Fetcher(
 
 // Fetch get the metrics that can be found in cgroups file system:
 // TODO: populate also network from libcgroups
-func (cg *CgroupsV1Fetcher) Fetch(c container.InspectResponse) (Metrics, error) {
+func (cg *CgroupsV1Fetcher) Fetch(_ context.Context, c container.InspectResponse) (Metrics, error) {
 	stats := Metrics{}
 
 	pid := c.State.Pid
@@ -96,6 +97,10 @@ func (cg *CgroupsV1Fetcher) Fetch(c container.InspectResponse) (Metrics, error)
 		log.Debug("couldn't read soft_limit_in_bytes stats: %v", err)
 	}
 
+	if c.Config != nil && IsKubernetesContainer(c.Config.Labels) {
+		cg.podLimits(cgroupInfo, c.HostConfig, &stats)
+	}
+
 	stats.ContainerID = containerID
 	stats.Network, err = cg.networkStatsGetter.GetForContainer(cg.hostRoot, strconv.Itoa(pid), containerID)
 
@@ -4,6 +4,7 @@
 package raw
 
 import (
+	"context"
 	"errors"
 	"path/filepath"
 	"strconv"
@@ -43,7 +44,7 @@ func NewCgroupsV2Fetcher(
 
 // Fetch get the metrics that can be found in cgroups v2 file system
 // Unlike v1, cgroup v2 has only single hierarchy.
-func (cg *CgroupsV2Fetcher) Fetch(containerInfo container.InspectResponse) (Metrics, error) {
+func (cg *CgroupsV2Fetcher) Fetch(_ context.Context, containerInfo container.InspectResponse) (Metrics, error) {
 	stats := Metrics{}
 
 	pid := containerInfo.State.Pid
@@ -89,6 +90,10 @@ func (cg *CgroupsV2Fetcher) Fetch(containerInfo container.InspectResponse) (Metr
 		log.Error("couldn't read memory stats: %v", err)
 	}
 
+	if containerInfo.Config != nil && IsKubernetesContainer(containerInfo.Config.Labels) {
+		cg.podLimits(cgroupInfo, containerInfo.HostConfig, &stats)
+	}
+
 	if stats.Blkio, err = cg.io(metrics); err != nil {
 		log.Error("couldn't read io stats: %v", err)
 	}
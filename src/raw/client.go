@@ -5,6 +5,7 @@ import (
 	"io"
 
 	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/swarm"
 )
 
 // DockerInspector includes `Informer` and a method to inspect a specific container.
@@ -18,6 +19,12 @@ type DockerClient interface {
 	ContainerList(ctx context.Context, all bool) ([]container.Summary, error)
 }
 
+// VersionedClient is implemented by the clients aware of the API version negotiated with the daemon.
+type VersionedClient interface {
+	APIVersion() string
+	APIFeatures() APIFeatures
+}
+
 // ContainerStatsResponse wraps the response from ContainerStats.
 type ContainerStatsResponse struct {
 	Body io.ReadCloser
@@ -27,3 +34,11 @@ type ContainerStatsResponse struct {
 type DockerStatsClient interface {
 	ContainerStats(ctx context.Context, containerID string, stream bool) (ContainerStatsResponse, error)
 }
+
+// SwarmClient defines how to access the Swarm cluster state through the docker API. It is only available
+// on Swarm manager nodes.
+type SwarmClient interface {
+	ServiceList(ctx context.Context) ([]swarm.Service, error)
+	TaskList(ctx context.Context) ([]swarm.Task, error)
+	NodeList(ctx context.Context) ([]swarm.Node, error)
+}
@@ -0,0 +1,31 @@
+package raw
+
+import "github.com/moby/moby/client/pkg/versions"
+
+const (
+	// oneShotStatsAPIVersion is the first API version whose stats endpoint returns a single sample right away,
+	// instead of waiting a second to collect the previous one.
+	oneShotStatsAPIVersion = "1.41"
+	// onlineCPUsAPIVersion is the first API version reporting the online CPUs in the container stats.
+	onlineCPUsAPIVersion = "1.27"
+)
+
+// APIFeatures holds the Docker API features which depend on the API version used to talk to the daemon.
+type APIFeatures struct {
+	// OneShotStats is set if the stats can be fetched without the previous sample.
+	OneShotStats bool
+	// OnlineCPUs is set if the stats report the online CPUs, otherwise they are computed from the per-CPU usage.
+	OnlineCPUs bool
+}
+
+// NewAPIFeatures returns the features available in the given API version. An empty version is assumed to be
+// the latest one.
+func NewAPIFeatures(apiVersion string) APIFeatures {
+	if apiVersion == "" {
+		return APIFeatures{OneShotStats: true, OnlineCPUs: true}
+	}
+	return APIFeatures{
+		OneShotStats: versions.GreaterThanOrEqualTo(apiVersion, oneShotStatsAPIVersion),
+		OnlineCPUs:   versions.GreaterThanOrEqualTo(apiVersion, onlineCPUsAPIVersion),
+	}
+}
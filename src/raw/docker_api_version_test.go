@@ -0,0 +1,14 @@
+package raw
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAPIFeatures(t *testing.T) {
+	assert.Equal(t, APIFeatures{OneShotStats: true, OnlineCPUs: true}, NewAPIFeatures(""))
+	assert.Equal(t, APIFeatures{OneShotStats: true, OnlineCPUs: true}, NewAPIFeatures("1.44"))
+	assert.Equal(t, APIFeatures{OneShotStats: false, OnlineCPUs: true}, NewAPIFeatures("1.40"))
+	assert.Equal(t, APIFeatures{OneShotStats: false, OnlineCPUs: false}, NewAPIFeatures("1.24"))
+}
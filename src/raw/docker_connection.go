@@ -0,0 +1,138 @@
+package raw
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/docker/go-connections/tlsconfig"
+	"github.com/moby/moby/client"
+)
+
+const (
+	defaultDockerContext = "default"
+	// sshDummyHost is the host of the requests sent through SSH connections, which is ignored by the daemon.
+	sshDummyHost = "http://docker.example.com"
+)
+
+// DockerConnection defines how to connect to the Docker daemon. Empty fields fall back to the DOCKER_HOST,
+// DOCKER_CERT_PATH and DOCKER_TLS_VERIFY environment variables, as the Docker CLI does.
+type DockerConnection struct {
+	// Host is the daemon URL, e.g. unix:///var/run/docker.sock, tcp://10.0.0.1:2376 or ssh://user@host.
+	Host string
+	// Context is the name of a Docker CLI context to take the host and TLS configuration from. Fields set
+	// explicitly take precedence over the context ones.
+	Context string
+	// TLSCACert, TLSCert and TLSKey are the paths to the CA certificate, client certificate and client key
+	// used for TCP connections.
+	TLSCACert string
+	TLSCert   string
+	TLSKey    string
+	// TLSSkipVerify disables the daemon certificate verification.
+	TLSSkipVerify bool
+}
+
+// Resolve returns the connection with the settings of its Docker context, if any, and the daemon host set.
+func (conn DockerConnection) Resolve() (DockerConnection, error) {
+	if conn.Context != "" && conn.Context != defaultDockerContext {
+		contextConn, err := loadDockerContext(dockerConfigDir(), conn.Context)
+		if err != nil {
+			return conn, err
+		}
+		conn = conn.withDefaults(contextConn)
+	}
+	conn.Host = conn.host()
+	return conn, nil
+}
+
+// NewDockerClient returns a Docker client connected as defined by conn, which must have been resolved with
+// Resolve, so its Docker context is not loaded again. The given options are applied after the connection ones.
+func NewDockerClient(conn DockerConnection, opts ...client.Opt) (*client.Client, error) {
+	connOpts, err := conn.clientOpts()
+	if err != nil {
+		return nil, err
+	}
+
+	dockerClient, err := client.New(append(connOpts, opts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("creating Docker client for %s: %w", conn.host(), err)
+	}
+	return dockerClient, nil
+}
+
+func (conn DockerConnection) clientOpts() ([]client.Opt, error) {
+	opts := []client.Opt{client.FromEnv}
+
+	if conn.TLSCACert != "" || conn.TLSCert != "" || conn.TLSKey != "" || conn.TLSSkipVerify {
+		tlsConfig, err := tlsconfig.Client(tlsconfig.Options{
+			CAFile:             conn.TLSCACert,
+			CertFile:           conn.TLSCert,
+			KeyFile:            conn.TLSKey,
+			InsecureSkipVerify: conn.TLSSkipVerify,
+			ExclusiveRootPools: true,
+			MinVersion:         tls.VersionTLS12,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("configuring Docker client TLS: %w", err)
+		}
+		opts = append(opts, client.WithHTTPClient(&http.Client{
+			Transport:     &http.Transport{TLSClientConfig: tlsConfig},
+			CheckRedirect: client.CheckRedirect,
+		}))
+	}
+
+	host := conn.host()
+	hostURL, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("parsing Docker host %q: %w", host, err)
+	}
+
+	if hostURL.Scheme != "ssh" {
+		// the host is always set, so the transport is configured even if the HTTP client was replaced above
+		return append(opts, client.WithHost(host)), nil
+	}
+
+	dialer, err := newSSHDialer(hostURL)
+	if err != nil {
+		return nil, err
+	}
+	return append(opts,
+		client.WithHost(sshDummyHost),
+		client.WithDialContext(func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx)
+		}),
+	), nil
+}
+
+// host returns the daemon URL the connection is made to.
+func (conn DockerConnection) host() string {
+	if conn.Host != "" {
+		return conn.Host
+	}
+	if host := os.Getenv(client.EnvOverrideHost); host != "" {
+		return host
+	}
+	return client.DefaultDockerHost
+}
+
+// withDefaults returns the connection with the empty fields taken from defaults.
+func (conn DockerConnection) withDefaults(defaults DockerConnection) DockerConnection {
+	if conn.Host == "" {
+		conn.Host = defaults.Host
+	}
+	if conn.TLSCACert == "" {
+		conn.TLSCACert = defaults.TLSCACert
+	}
+	if conn.TLSCert == "" {
+		conn.TLSCert = defaults.TLSCert
+	}
+	if conn.TLSKey == "" {
+		conn.TLSKey = defaults.TLSKey
+	}
+	conn.TLSSkipVerify = conn.TLSSkipVerify || defaults.TLSSkipVerify
+	return conn
+}
@@ -0,0 +1,87 @@
+package raw
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	dockerConfigDirEnv = "DOCKER_CONFIG"
+	dockerEndpoint     = "docker"
+)
+
+// dockerContextMeta is the subset of the Docker CLI context metadata (contexts/meta/<id>/meta.json) used
+// to connect to the daemon.
+type dockerContextMeta struct {
+	Name      string
+	Endpoints map[string]struct {
+		Host          string
+		SkipTLSVerify bool
+	}
+}
+
+// dockerConfigDir returns the Docker CLI configuration directory, as the Docker CLI does.
+func dockerConfigDir() string {
+	if dir := os.Getenv(dockerConfigDirEnv); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".docker"
+	}
+	return filepath.Join(home, ".docker")
+}
+
+// loadDockerContext returns the connection of the Docker CLI context with the given name, stored in configDir.
+func loadDockerContext(configDir, name string) (DockerConnection, error) {
+	// contexts are stored in directories named after the digest of their name
+	digest := sha256.Sum256([]byte(name))
+	id := hex.EncodeToString(digest[:])
+
+	metaPath := filepath.Join(configDir, "contexts", "meta", id, "meta.json")
+	content, err := os.ReadFile(metaPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return DockerConnection{}, fmt.Errorf("docker context %q not found in %s", name, configDir)
+	}
+	if err != nil {
+		return DockerConnection{}, fmt.Errorf("reading docker context %q: %w", name, err)
+	}
+
+	meta := dockerContextMeta{}
+	if err := json.Unmarshal(content, &meta); err != nil {
+		return DockerConnection{}, fmt.Errorf("parsing docker context %q metadata %s: %w", name, metaPath, err)
+	}
+	endpoint, ok := meta.Endpoints[dockerEndpoint]
+	if !ok || endpoint.Host == "" {
+		return DockerConnection{}, fmt.Errorf("docker context %q has no docker endpoint", name)
+	}
+
+	conn := DockerConnection{
+		Host:          endpoint.Host,
+		TLSSkipVerify: endpoint.SkipTLSVerify,
+	}
+
+	// TLS material is optional, and stored in contexts/tls/<id>/docker/
+	tlsDir := filepath.Join(configDir, "contexts", "tls", id, dockerEndpoint)
+	for file, field := range map[string]*string{
+		"ca.pem":   &conn.TLSCACert,
+		"cert.pem": &conn.TLSCert,
+		"key.pem":  &conn.TLSKey,
+	} {
+		if path := filepath.Join(tlsDir, file); fileExists(path) {
+			*field = path
+		}
+	}
+
+	return conn, nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
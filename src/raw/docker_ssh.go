@@ -0,0 +1,156 @@
+package raw
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxSSHStderrBytes limits the ssh command output kept to report connection errors.
+const maxSSHStderrBytes = 4096
+
+// sshDialer connects to a remote Docker daemon running `docker system dial-stdio` through the ssh command,
+// as the Docker CLI does, so the user ssh configuration (keys, agent, known hosts) is honored.
+type sshDialer struct {
+	host string
+	args []string
+}
+
+func newSSHDialer(u *url.URL) (*sshDialer, error) {
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid ssh Docker host %q: no host", u.Redacted())
+	}
+	if u.Path != "" && u.Path != "/" {
+		return nil, fmt.Errorf("invalid ssh Docker host %q: paths are not supported", u.Redacted())
+	}
+
+	var args []string
+	if u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			return nil, fmt.Errorf("invalid ssh Docker host %q: passwords are not supported, use keys instead", u.Redacted())
+		}
+		args = append(args, "-l", u.User.Username())
+	}
+	if port := u.Port(); port != "" {
+		args = append(args, "-p", port)
+	}
+	args = append(args, "--", u.Hostname(), "docker", "system", "dial-stdio")
+
+	return &sshDialer{host: u.Redacted(), args: args}, nil
+}
+
+// DialContext starts a new ssh process whose stdin and stdout are used as the connection.
+func (d *sshDialer) DialContext(ctx context.Context) (net.Conn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// the process must outlive the dial context, as the connection is reused by the HTTP client
+	cmd := exec.Command("ssh", d.args...) //nolint:gosec // the arguments come from the integration configuration
+	conn, err := startCommandConn(cmd, d.host)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to Docker host %s: %w", d.host, err)
+	}
+	return conn, nil
+}
+
+// commandConn is a net.Conn over the standard input and output of a process.
+type commandConn struct {
+	cmd       *exec.Cmd
+	host      string
+	stdin     io.WriteCloser
+	stdout    io.ReadCloser
+	stderr    *limitedBuffer
+	closeOnce sync.Once
+}
+
+// startCommandConn starts the command, whose standard input and output become the connection.
+func startCommandConn(cmd *exec.Cmd, host string) (*commandConn, error) {
+	conn := &commandConn{cmd: cmd, host: host, stderr: &limitedBuffer{max: maxSSHStderrBytes}}
+	cmd.Stderr = conn.stderr
+
+	var err error
+	if conn.stdin, err = cmd.StdinPipe(); err != nil {
+		return nil, err
+	}
+	if conn.stdout, err = cmd.StdoutPipe(); err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (c *commandConn) Read(p []byte) (int, error) {
+	n, err := c.stdout.Read(p)
+	if errors.Is(err, io.EOF) {
+		if stderr := c.stderr.String(); stderr != "" {
+			return n, fmt.Errorf("ssh connection to Docker host %s closed: %s", c.host, strings.TrimSpace(stderr))
+		}
+	}
+	return n, err
+}
+
+func (c *commandConn) Write(p []byte) (int, error) {
+	return c.stdin.Write(p)
+}
+
+func (c *commandConn) Close() error {
+	c.closeOnce.Do(func() {
+		_ = c.stdin.Close()
+		_ = c.stdout.Close()
+		if c.cmd.Process != nil {
+			_ = c.cmd.Process.Kill()
+		}
+		_ = c.cmd.Wait()
+	})
+	return nil
+}
+
+func (c *commandConn) LocalAddr() net.Addr  { return sshAddr(c.host) }
+func (c *commandConn) RemoteAddr() net.Addr { return sshAddr(c.host) }
+
+// Deadlines are not supported by the process pipes, and the HTTP client timeouts still apply.
+func (c *commandConn) SetDeadline(time.Time) error      { return nil }
+func (c *commandConn) SetReadDeadline(time.Time) error  { return nil }
+func (c *commandConn) SetWriteDeadline(time.Time) error { return nil }
+
+type sshAddr string
+
+func (a sshAddr) Network() string { return "ssh" }
+func (a sshAddr) String() string  { return string(a) }
+
+// limitedBuffer is a concurrency-safe buffer which discards the writes beyond its max size.
+type limitedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+	max int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if remaining := b.max - b.buf.Len(); remaining > 0 {
+		if len(p) > remaining {
+			b.buf.Write(p[:remaining])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
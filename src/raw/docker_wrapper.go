@@ -4,19 +4,44 @@ import (
 	"context"
 
 	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/swarm"
 	"github.com/moby/moby/api/types/system"
 	"github.com/moby/moby/client"
 )
 
 // DockerClientWrapper wraps a *client.Client and adapts it to the
-// DockerClient, DockerStatsClient, and DockerInspector interfaces.
+// DockerClient, DockerStatsClient, DockerInspector and VersionedClient interfaces.
 type DockerClientWrapper struct {
-	client *client.Client
+	client     *client.Client
+	apiVersion string
+	features   APIFeatures
 }
 
-// NewDockerClientWrapper creates a new DockerClientWrapper.
+// NewDockerClientWrapper creates a new DockerClientWrapper. All the API features are assumed to be available
+// until NegotiateAPIVersion is called.
 func NewDockerClientWrapper(c *client.Client) *DockerClientWrapper {
-	return &DockerClientWrapper{client: c}
+	return &DockerClientWrapper{client: c, features: NewAPIFeatures("")}
+}
+
+// NegotiateAPIVersion agrees the API version with the daemon, unless it was set explicitly, and returns the
+// version used.
+func (w *DockerClientWrapper) NegotiateAPIVersion(ctx context.Context) (string, error) {
+	if _, err := w.client.Ping(ctx, client.PingOptions{NegotiateAPIVersion: true}); err != nil {
+		return "", err
+	}
+	w.apiVersion = w.client.ClientVersion()
+	w.features = NewAPIFeatures(w.apiVersion)
+	return w.apiVersion, nil
+}
+
+// APIVersion returns the API version used to talk to the daemon, or an empty string if it was not negotiated yet.
+func (w *DockerClientWrapper) APIVersion() string {
+	return w.apiVersion
+}
+
+// APIFeatures returns the features available in the API version used to talk to the daemon.
+func (w *DockerClientWrapper) APIFeatures() APIFeatures {
+	return w.features
 }
 
 func (w *DockerClientWrapper) ContainerInspect(ctx context.Context, containerID string) (container.InspectResponse, error) {
@@ -36,7 +61,11 @@ func (w *DockerClientWrapper) ContainerList(ctx context.Context, all bool) ([]co
 }
 
 func (w *DockerClientWrapper) ContainerStats(ctx context.Context, containerID string, stream bool) (ContainerStatsResponse, error) {
-	result, err := w.client.ContainerStats(ctx, containerID, client.ContainerStatsOptions{Stream: stream})
+	result, err := w.client.ContainerStats(ctx, containerID, client.ContainerStatsOptions{
+		Stream: stream,
+		// daemons older than one-shot stats always wait for the previous sample, so it is asked explicitly
+		IncludePreviousSample: !w.features.OneShotStats,
+	})
 	if err != nil {
 		return ContainerStatsResponse{}, err
 	}
@@ -52,6 +81,33 @@ func (w *DockerClientWrapper) Info(ctx context.Context) (system.Info, error) {
 	return result.Info, nil
 }
 
+// ServiceList returns the Swarm services including their running and desired tasks count.
+func (w *DockerClientWrapper) ServiceList(ctx context.Context) ([]swarm.Service, error) {
+	result, err := w.client.ServiceList(ctx, client.ServiceListOptions{Status: true})
+	if err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+// TaskList returns the Swarm tasks.
+func (w *DockerClientWrapper) TaskList(ctx context.Context) ([]swarm.Task, error) {
+	result, err := w.client.TaskList(ctx, client.TaskListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+// NodeList returns the Swarm nodes.
+func (w *DockerClientWrapper) NodeList(ctx context.Context) ([]swarm.Node, error) {
+	result, err := w.client.NodeList(ctx, client.NodeListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
 // Close closes the underlying client.
 func (w *DockerClientWrapper) Close() error {
 	return w.client.Close()
@@ -21,8 +21,8 @@ func NewFetcher(statsClient raw.DockerStatsClient, platform string) *Fetcher {
 	return &Fetcher{statsClient: statsClient, platform: platform}
 }
 
-func (f *Fetcher) Fetch(container container.InspectResponse) (raw.Metrics, error) {
-	containerStats, err := f.containerStats(context.Background(), container.ID)
+func (f *Fetcher) Fetch(ctx context.Context, container container.InspectResponse) (raw.Metrics, error) {
+	containerStats, err := f.containerStats(ctx, container.ID)
 	if err != nil {
 		return raw.Metrics{}, fmt.Errorf("could not fetch stats for container %s: %w", container.ID, err)
 	}
@@ -103,7 +103,7 @@ func (f *Fetcher) cpuMetrics(container container.InspectResponse, containerStats
 		ThrottledPeriods:  cpuStats.ThrottlingData.ThrottledPeriods,
 		ThrottledTimeNS:   cpuStats.ThrottlingData.ThrottledTime,
 		SystemUsage:       cpuStats.SystemUsage,
-		OnlineCPUs:        uint(cpuStats.OnlineCPUs),
+		OnlineCPUs:        f.onlineCPUs(cpuStats),
 		Shares:            cpuShares,
 		// PercpuUsage is not set in cgroups v2 (it is set to nil) but it is not reported by the integration,
 		// it is used to report the 'OnlineCPUs' value when online CPUs cannot be fetched.
@@ -115,6 +115,15 @@ func (f *Fetcher) cpuMetrics(container container.InspectResponse, containerStats
 	}
 }
 
+// onlineCPUs returns the online CPUs reported in the stats, or 0 if the API version does not report them, so they
+// are computed from the per-CPU usage.
+func (f *Fetcher) onlineCPUs(cpuStats container.CPUStats) uint {
+	if versioned, ok := f.statsClient.(raw.VersionedClient); ok && !versioned.APIFeatures().OnlineCPUs {
+		return 0
+	}
+	return uint(cpuStats.OnlineCPUs)
+}
+
 func (f *Fetcher) pidsMetrics(pidStats container.PidsStats) raw.Pids {
 	return raw.Pids{
 		Current: pidStats.Current,
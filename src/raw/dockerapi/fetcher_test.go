@@ -102,7 +102,7 @@ func Test_Fetch(t *testing.T) {
 
 	fetcher := dockerapi.NewFetcher(&client, constants.LinuxPlatformName)
 
-	metrics, err := fetcher.Fetch(container.InspectResponse{
+	metrics, err := fetcher.Fetch(context.Background(), container.InspectResponse{
 		ID: "test",
 		HostConfig: &container.HostConfig{
 			Resources: container.Resources{
@@ -196,10 +196,35 @@ func Test_NilHostConfig(t *testing.T) {
 
 	fetcher := dockerapi.NewFetcher(&client, constants.LinuxPlatformName)
 
-	metricsNoHostConfig, err := fetcher.Fetch(container.InspectResponse{ID: "test"})
+	metricsNoHostConfig, err := fetcher.Fetch(context.Background(), container.InspectResponse{ID: "test"})
 	require.NoError(t, err)
 
 	assert.EqualValues(t, 0, metricsNoHostConfig.CPU.Shares, "When hostConfig is not available, cpu shares cannot be set")
 	assert.EqualValues(t, 0, metricsNoHostConfig.Memory.SwapLimit, "When hostConfig is not available, SwapLimit cannot be set")
 	assert.EqualValues(t, 0, metricsNoHostConfig.Memory.SoftLimit, "When hostConfig is not available, SoftLimit cannot be set")
 }
+
+// mockVersionedStatsClient is a stats client aware of the negotiated API version.
+type mockVersionedStatsClient struct {
+	mockDockerStatsClient
+	apiVersion string
+}
+
+func (m *mockVersionedStatsClient) APIVersion() string {
+	return m.apiVersion
+}
+
+func (m *mockVersionedStatsClient) APIFeatures() raw.APIFeatures {
+	return raw.NewAPIFeatures(m.apiVersion)
+}
+
+func Test_OnlineCPUsFeature(t *testing.T) {
+	for apiVersion, expected := range map[string]uint{"1.44": 2, "1.25": 0} {
+		client := mockVersionedStatsClient{apiVersion: apiVersion}
+		client.On("ContainerStats", mock.Anything).Return(mockStats)
+
+		metrics, err := dockerapi.NewFetcher(&client, constants.LinuxPlatformName).Fetch(context.Background(), container.InspectResponse{ID: "test"})
+		require.NoError(t, err)
+		assert.Equal(t, expected, metrics.CPU.OnlineCPUs, "API version %s", apiVersion)
+	}
+}
@@ -0,0 +1,17 @@
+package raw
+
+// Synthetic label set by the ECS inspectors on the containers of ECS tasks.
+const (
+	// LaunchTypeLabel is a synthetic label with the lowercased launch type of the task of the container.
+	LaunchTypeLabel = "com.newrelic.nri-docker.launch-type"
+
+	// FargateLaunchType and EC2LaunchType are the values of the LaunchTypeLabel for the containers of Fargate and
+	// ECS on EC2 tasks.
+	FargateLaunchType = "fargate"
+	EC2LaunchType     = "ec2"
+)
+
+// IsFargateContainer returns true if the labels belong to a container of a Fargate task.
+func IsFargateContainer(labels map[string]string) bool {
+	return labels[LaunchTypeLabel] == FargateLaunchType
+}
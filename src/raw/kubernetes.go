@@ -0,0 +1,23 @@
+package raw
+
+// Labels set by dockershim/cri-dockerd on the containers it creates on behalf of the kubelet.
+const (
+	KubernetesPodNameLabel       = "io.kubernetes.pod.name"
+	KubernetesPodNamespaceLabel  = "io.kubernetes.pod.namespace"
+	KubernetesContainerNameLabel = "io.kubernetes.container.name"
+	KubernetesContainerTypeLabel = "io.kubernetes.docker.type"
+
+	// KubernetesSandboxType is the value of the KubernetesContainerTypeLabel for pod sandbox ("pause") containers.
+	KubernetesSandboxType = "podsandbox"
+)
+
+// IsKubernetesContainer returns true if the labels belong to a container created by cri-dockerd for a pod.
+func IsKubernetesContainer(labels map[string]string) bool {
+	_, ok := labels[KubernetesPodNameLabel]
+	return ok
+}
+
+// IsKubernetesSandbox returns true if the labels belong to the sandbox ("pause") container of a pod.
+func IsKubernetesSandbox(labels map[string]string) bool {
+	return labels[KubernetesContainerTypeLabel] == KubernetesSandboxType
+}
@@ -0,0 +1,127 @@
+//go:build linux
+
+package raw
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/containerd/cgroups"
+	"github.com/moby/moby/api/types/container"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+// podLimits replaces the memory and CPU limits of a Kubernetes container which has no limits of its own
+// with the ones set in the pod cgroup, which is the parent of the container cgroup in the kubepods hierarchy.
+func (cg *CgroupsV1Fetcher) podLimits(cgroupInfo CgroupsV1PathGetter, hostConfig *container.HostConfig, stats *Metrics) {
+	if isUnlimited(stats.Memory.UsageLimit) {
+		if memoryPath, err := cgroupInfo.getFullPath(cgroups.Memory); err == nil {
+			limit, err := podUintStat(memoryPath, "memory.limit_in_bytes")
+			if err != nil {
+				log.Debug("couldn't read pod memory limit: %v", err)
+			} else if !isUnlimited(limit) {
+				stats.Memory.UsageLimit = limit
+			}
+		}
+	}
+
+	if hasCPULimit(hostConfig) {
+		return
+	}
+	cpuPath, err := cgroupInfo.getFullPath(cgroups.Cpu)
+	if err != nil {
+		return
+	}
+	quota, err := podUintStat(cpuPath, "cpu.cfs_quota_us")
+	if err != nil {
+		log.Debug("couldn't read pod cpu quota: %v", err)
+		return
+	}
+	period, err := podUintStat(cpuPath, "cpu.cfs_period_us")
+	if err != nil {
+		log.Debug("couldn't read pod cpu period: %v", err)
+		return
+	}
+	stats.CPU.LimitCores = quotaCores(quota, period)
+}
+
+// podLimits replaces the memory and CPU limits of a Kubernetes container which has no limits of its own
+// with the ones set in the pod cgroup, which is the parent of the container cgroup in the kubepods hierarchy.
+func (cg *CgroupsV2Fetcher) podLimits(cgroupInfo CgroupV2PathGetter, hostConfig *container.HostConfig, stats *Metrics) {
+	containerPath := cgroupInfo.getFullPath()
+
+	if isUnlimited(stats.Memory.UsageLimit) {
+		limit, err := podUintStat(containerPath, "memory.max")
+		if err != nil {
+			log.Debug("couldn't read pod memory limit: %v", err)
+		} else if !isUnlimited(limit) {
+			stats.Memory.UsageLimit = limit
+		}
+	}
+
+	if hasCPULimit(hostConfig) {
+		return
+	}
+	limitCores, err := podCPUMax(containerPath)
+	if err != nil {
+		log.Debug("couldn't read pod cpu limit: %v", err)
+		return
+	}
+	stats.CPU.LimitCores = limitCores
+}
+
+// podUintStat reads a single value stat file from the parent of the given container cgroup path.
+func podUintStat(containerPath, stat string) (uint64, error) {
+	return ParseStatFileContentUint64(filepath.Join(filepath.Dir(containerPath), stat))
+}
+
+// podCPUMax parses the cgroups v2 "cpu.max" file of the parent of the given container cgroup path,
+// which has the "$MAX $PERIOD" format. Zero is returned if there is no limit.
+func podCPUMax(containerPath string) (float64, error) {
+	filePath := filepath.Join(filepath.Dir(containerPath), "cpu.max")
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return 0, err
+	}
+
+	fields := strings.Fields(string(contents))
+	if len(fields) != 2 {
+		return 0, fmt.Errorf("unexpected format in Cgroup file %q: %q", filePath, string(contents))
+	}
+	if fields[0] == "max" {
+		return 0, nil
+	}
+
+	quota, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse quota from Cgroup file %q: %w", filePath, err)
+	}
+	period, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse period from Cgroup file %q: %w", filePath, err)
+	}
+	return quotaCores(quota, period), nil
+}
+
+// quotaCores converts a CFS quota and period to a number of cores. A non-positive quota (-1 in cgroups v1)
+// is parsed as zero and means no limit.
+func quotaCores(quota, period uint64) float64 {
+	if quota == 0 || period == 0 {
+		return 0
+	}
+	return float64(quota) / float64(period)
+}
+
+// isUnlimited returns true for memory limits that are not set, which the kernel reports as a
+// huge number (page-aligned MaxInt64 in cgroups v1, "max" in cgroups v2).
+func isUnlimited(limit uint64) bool {
+	return limit == 0 || limit > math.MaxInt64/2
+}
+
+func hasCPULimit(hostConfig *container.HostConfig) bool {
+	return hostConfig != nil && (hostConfig.NanoCPUs != 0 || hostConfig.CPUQuota > 0)
+}
@@ -0,0 +1,102 @@
+//go:build linux
+
+package raw
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCgroupFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, os.ModePerm))
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+}
+
+func TestCgroupsV2PodLimits(t *testing.T) {
+	td := t.TempDir()
+	podPath := filepath.Join(td, "kubepods.slice", "kubepods-pod1234.slice")
+	writeCgroupFiles(t, podPath, map[string]string{
+		"memory.max": "536870912\n",
+		"cpu.max":    "150000 100000\n",
+	})
+
+	cgroupInfo := &cgroupV2Paths{mountPoint: podPath, group: "/docker-f7bd95ec.scope"}
+
+	t.Run("container without limits inherits the pod ones", func(t *testing.T) {
+		stats := Metrics{Memory: Memory{UsageLimit: math.MaxUint64}}
+		(&CgroupsV2Fetcher{}).podLimits(cgroupInfo, &container.HostConfig{}, &stats)
+
+		assert.Equal(t, uint64(536870912), stats.Memory.UsageLimit)
+		assert.Equal(t, 1.5, stats.CPU.LimitCores)
+	})
+
+	t.Run("container limits are kept", func(t *testing.T) {
+		stats := Metrics{Memory: Memory{UsageLimit: 1024}}
+		(&CgroupsV2Fetcher{}).podLimits(cgroupInfo, &container.HostConfig{
+			Resources: container.Resources{CPUQuota: 50000},
+		}, &stats)
+
+		assert.Equal(t, uint64(1024), stats.Memory.UsageLimit)
+		assert.Zero(t, stats.CPU.LimitCores)
+	})
+}
+
+func TestCgroupsV2PodLimitsUnlimitedPod(t *testing.T) {
+	td := t.TempDir()
+	writeCgroupFiles(t, td, map[string]string{
+		"memory.max": "max\n",
+		"cpu.max":    "max 100000\n",
+	})
+
+	stats := Metrics{Memory: Memory{UsageLimit: math.MaxUint64}}
+	cgroupInfo := &cgroupV2Paths{mountPoint: td, group: "/docker-f7bd95ec.scope"}
+	(&CgroupsV2Fetcher{}).podLimits(cgroupInfo, nil, &stats)
+
+	assert.Equal(t, uint64(math.MaxUint64), stats.Memory.UsageLimit)
+	assert.Zero(t, stats.CPU.LimitCores)
+}
+
+func TestCgroupsV1PodLimits(t *testing.T) {
+	td := t.TempDir()
+	writeCgroupFiles(t, filepath.Join(td, "memory", "kubepods", "pod1234"), map[string]string{
+		"memory.limit_in_bytes": "268435456\n",
+	})
+	writeCgroupFiles(t, filepath.Join(td, "cpu", "kubepods", "pod1234"), map[string]string{
+		"cpu.cfs_quota_us":  "50000\n",
+		"cpu.cfs_period_us": "100000\n",
+	})
+
+	cgroupInfo := &cgroupV1Paths{
+		mountPoints: map[string]string{"memory": td, "cpu": td},
+		paths: map[string]string{
+			"memory": "/kubepods/pod1234/f7bd95ec",
+			"cpu":    "/kubepods/pod1234/f7bd95ec",
+		},
+	}
+
+	stats := Metrics{Memory: Memory{UsageLimit: 9223372036854771712}}
+	(&CgroupsV1Fetcher{}).podLimits(cgroupInfo, nil, &stats)
+
+	assert.Equal(t, uint64(268435456), stats.Memory.UsageLimit)
+	assert.Equal(t, 0.5, stats.CPU.LimitCores)
+}
+
+func TestPodCPUMaxErrors(t *testing.T) {
+	td := t.TempDir()
+	writeCgroupFiles(t, td, map[string]string{"cpu.max": "100000\n"})
+
+	_, err := podCPUMax(filepath.Join(td, "container"))
+	assert.Error(t, err)
+
+	_, err = podCPUMax(filepath.Join(td, "missing", "container"))
+	assert.Error(t, err)
+}
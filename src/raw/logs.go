@@ -0,0 +1,65 @@
+package raw
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/moby/moby/api/types/container"
+)
+
+const (
+	// LogDriverJSONFile is the default Docker log driver, which stores the logs in a JSON file per container.
+	LogDriverJSONFile = "json-file"
+	// LogDriverLocal is the Docker log driver storing the logs in a compressed, file-based format.
+	LogDriverLocal = "local"
+
+	localLogsDir  = "local-logs"
+	localLogsFile = "container.log"
+)
+
+// ContainerLogPath returns the path of the log file of a container, only for the log drivers that store
+// the logs on the host filesystem. An empty string is returned otherwise.
+func ContainerLogPath(json container.InspectResponse) string {
+	if json.HostConfig == nil {
+		return ""
+	}
+	switch json.HostConfig.LogConfig.Type {
+	case LogDriverJSONFile:
+		return json.LogPath
+	case LogDriverLocal:
+		// LogPath is not set for the local driver. Its logs are stored in the container folder,
+		// which is the same one holding the resolv.conf file.
+		if json.ResolvConfPath == "" {
+			return ""
+		}
+		return filepath.Join(filepath.Dir(json.ResolvConfPath), localLogsDir, localLogsFile)
+	}
+	return ""
+}
+
+// LogFilesSize returns the size in bytes of the given log file, which is the active one, and the total size of
+// it plus its rotated files, which share the same prefix (e.g. <id>-json.log.1 or container.log.2.gz).
+func LogFilesSize(logPath string) (uint64, uint64, error) {
+	active, err := os.Stat(logPath)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	files, err := filepath.Glob(logPath + "*")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var size uint64
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			// the file could have been removed by the rotation
+			continue
+		}
+		if info.Mode().IsRegular() {
+			size += uint64(info.Size())
+		}
+	}
+	return uint64(active.Size()), size, nil
+}
@@ -0,0 +1,70 @@
+package raw
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainerLogPath(t *testing.T) {
+	tests := []struct {
+		name string
+		json container.InspectResponse
+		want string
+	}{
+		{
+			name: "json-file driver",
+			json: container.InspectResponse{
+				LogPath:    "/var/lib/docker/containers/abc/abc-json.log",
+				HostConfig: &container.HostConfig{LogConfig: container.LogConfig{Type: "json-file"}},
+			},
+			want: "/var/lib/docker/containers/abc/abc-json.log",
+		},
+		{
+			name: "local driver",
+			json: container.InspectResponse{
+				ResolvConfPath: "/var/lib/docker/containers/abc/resolv.conf",
+				HostConfig:     &container.HostConfig{LogConfig: container.LogConfig{Type: "local"}},
+			},
+			want: "/var/lib/docker/containers/abc/local-logs/container.log",
+		},
+		{
+			name: "remote driver",
+			json: container.InspectResponse{
+				HostConfig: &container.HostConfig{LogConfig: container.LogConfig{Type: "awslogs"}},
+			},
+			want: "",
+		},
+		{
+			name: "missing host config",
+			json: container.InspectResponse{LogPath: "/var/lib/docker/containers/abc/abc-json.log"},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ContainerLogPath(tt.json))
+		})
+	}
+}
+
+func TestLogFilesSize(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "abc-json.log")
+	require.NoError(t, os.WriteFile(logPath, make([]byte, 100), 0o600))
+	require.NoError(t, os.WriteFile(logPath+".1", make([]byte, 20), 0o600))
+	require.NoError(t, os.WriteFile(logPath+".2.gz", make([]byte, 3), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "hostname"), make([]byte, 10), 0o600))
+
+	active, total, err := LogFilesSize(logPath)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), active)
+	assert.Equal(t, uint64(123), total)
+
+	_, _, err = LogFilesSize(filepath.Join(dir, "missing-json.log"))
+	assert.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package raw
 
 import (
+	"context"
 	"time"
 
 	"github.com/moby/moby/api/types/container"
@@ -52,6 +53,9 @@ type CPU struct {
 	NumProcs          uint32
 	PreRead           time.Time
 	Read              time.Time
+	// LimitCores is the CPU limit inherited from a parent cgroup (e.g. a Kubernetes pod), if the
+	// container has no limit of its own.
+	LimitCores float64
 }
 
 // Pids inside the container
@@ -86,11 +90,15 @@ type Network struct {
 	TxDropped int64
 	TxErrors  int64
 	TxPackets int64
+	// RxBytesPerSecond and TxBytesPerSecond are the rates reported by the source, if any, e.g. the ECS task
+	// metadata endpoint v4. They don't depend on the previous samples, unlike the rates computed from the counters.
+	RxBytesPerSecond *float64
+	TxBytesPerSecond *float64
 }
 
 // Fetcher is the minimal abstraction of any raw metrics fetcher implementation
 type Fetcher interface {
-	Fetch(container.InspectResponse) (Metrics, error)
+	Fetch(context.Context, container.InspectResponse) (Metrics, error)
 }
 
 // OnlineCPUsWithFallback gets onlineCPUs value falling back to percpuUsage length in case OnlineCPUs is not defined
@@ -30,7 +30,7 @@ func TestDockerAPIFetcher(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.EventuallyWithT(t, func(ct *assert.CollectT) {
-		statsData, err := fetcher.Fetch(inspectData)
+		statsData, err := fetcher.Fetch(context.Background(), inspectData)
 		require.NoError(ct, err)
 
 		// CPU metrics
@@ -3,6 +3,7 @@
 package integration
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -10,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/moby/moby/api/types/container"
+	"github.com/newrelic/infra-integrations-sdk/v3/data/inventory"
 	"github.com/newrelic/nri-docker/src/biz"
 	"github.com/newrelic/nri-docker/src/raw"
 	"github.com/newrelic/nri-docker/src/utils"
@@ -71,6 +73,18 @@ func TestCgroupsv2AllMetricsPresent(t *testing.T) {
 			SoftLimitBytes:        104857600,
 		},
 		RestartCount: 2,
+		Inventory: inventory.Items{
+			"resources": inventory.Item{
+				"cpuShares":              int64(cpuSharesValue),
+				"memoryReservationBytes": int64(memoryReservationValue),
+			},
+		},
+		// no user is set and the root filesystem is writable in the mocked configuration, scoring 10 and 5
+		Security: &biz.Security{
+			SeccompProfile: "default",
+			RunsAsRoot:     true,
+			RiskScore:      15,
+		},
 	}
 
 	hostRoot := t.TempDir()
@@ -104,7 +118,7 @@ func TestCgroupsv2AllMetricsPresent(t *testing.T) {
 		metrics := biz.NewProcessor(storer, cgroupFetcher, inspector, 0)
 		metrics.WithRuntimeNumCPUfunc(func() int { return 2 }) // Mocked cgroups are extracted from a 2 CPU machine.
 
-		sample, err := metrics.Process(InspectorContainerID)
+		sample, err := metrics.Process(context.Background(), InspectorContainerID)
 		require.NoError(t, err)
 
 		assert.Equal(t, expectedSample, sample)
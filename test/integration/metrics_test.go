@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/moby/moby/api/types/container"
+	"github.com/newrelic/infra-integrations-sdk/v3/data/inventory"
 	"github.com/newrelic/infra-integrations-sdk/v3/log"
 	"github.com/newrelic/infra-integrations-sdk/v3/persist"
 	"github.com/stretchr/testify/assert"
@@ -43,7 +44,7 @@ func TestCompareMetrics(t *testing.T) {
 		cgroupFetcher,
 		dockerClient,
 		0)
-	sampleCGroup, err := metricsCGroup.Process(containerID)
+	sampleCGroup, err := metricsCGroup.Process(context.Background(), containerID)
 	require.NoError(t, err)
 
 	// WHEN its metrics are sampled and processed from API Server
@@ -59,18 +60,18 @@ func TestCompareMetrics(t *testing.T) {
 		fetcherAPI,
 		dockerClient,
 		0)
-	sampleAPI, err := metricsAPI.Process(containerID)
+	sampleAPI, err := metricsAPI.Process(context.Background(), containerID)
 	require.NoError(t, err)
 
 	// Core metrics are calculated from metrics.Process time differences, using variables with seconds accuaracy. Use a tick larger than a second for accuracy.
 	assert.EventuallyWithT(t,
 		func(t *assert.CollectT) {
-			sampleAPI, err = metricsAPI.Process(containerID)
+			sampleAPI, err = metricsAPI.Process(context.Background(), containerID)
 			require.NoError(t, err)
 			data, _ := json.Marshal(sampleAPI)
 			log.Error("sampleAPI: %q", string(data))
 
-			sampleCGroup, err = metricsCGroup.Process(containerID)
+			sampleCGroup, err = metricsCGroup.Process(context.Background(), containerID)
 			require.NoError(t, err)
 			data, _ = json.Marshal(sampleCGroup)
 			log.Error("sampleCGroup: %q", string(data))
@@ -125,7 +126,7 @@ func TestHighCPU(t *testing.T) {
 		cgroupFetcher,
 		docker,
 		0)
-	sample, err := metrics.Process(containerID)
+	sample, err := metrics.Process(context.Background(), containerID)
 	require.NoError(t, err)
 
 	// THEN the CPU static metrics belong to the container
@@ -134,7 +135,7 @@ func TestHighCPU(t *testing.T) {
 
 	assert.EventuallyWithT(t,
 		func(t *assert.CollectT) {
-			sample, err := metrics.Process(containerID)
+			sample, err := metrics.Process(context.Background(), containerID)
 			require.NoError(t, err)
 
 			cpu := sample.CPU
@@ -177,7 +178,7 @@ func TestLowCPU(t *testing.T) {
 		cgroupFetcher,
 		docker,
 		0)
-	sample, err := metrics.Process(containerID)
+	sample, err := metrics.Process(context.Background(), containerID)
 	require.NoError(t, err)
 
 	// THEN the CPU static metrics belong to the container
@@ -187,7 +188,7 @@ func TestLowCPU(t *testing.T) {
 	assert.EventuallyWithT(
 		t,
 		func(t *assert.CollectT) {
-			sample, err := metrics.Process(containerID)
+			sample, err := metrics.Process(context.Background(), containerID)
 			require.NoError(t, err)
 
 			cpu := sample.CPU
@@ -221,7 +222,7 @@ func TestMemory(t *testing.T) {
 		0)
 	// Then the Memory metrics are reported according to the usage and limits
 	assert.EventuallyWithT(t, func(t *assert.CollectT) {
-		sample, err := metrics.Process(containerID)
+		sample, err := metrics.Process(context.Background(), containerID)
 		require.NoError(t, err)
 
 		mem := sample.Memory
@@ -255,7 +256,7 @@ func TestExitedContainersWithTTL(t *testing.T) {
 
 	// Then once the container is in exit status for more than the TTL, an error should be returned.
 	assert.EventuallyWithT(t, func(t *assert.CollectT) {
-		samples, err := metrics.Process(containerID)
+		samples, err := metrics.Process(context.Background(), containerID)
 		assert.ErrorIs(t, err, biz.ErrExitedContainerExpired)
 		assert.Empty(t, samples)
 	}, eventuallyTimeout, eventuallyTick)
@@ -276,14 +277,14 @@ func TestExitedContainersWithoutTTL(t *testing.T) {
 
 	// Container metrics should be fetched when running.
 	assert.EventuallyWithT(t, func(t *assert.CollectT) {
-		sample, err := metrics.Process(containerID)
+		sample, err := metrics.Process(context.Background(), containerID)
 		assert.NoError(t, err)
 		assert.NotEmpty(t, sample)
 	}, eventuallyTimeout, eventuallyTick)
 
 	// Then once the container is in exit status metrics are not fetched.
 	assert.EventuallyWithT(t, func(t *assert.CollectT) {
-		sample, err := metrics.Process(containerID)
+		sample, err := metrics.Process(context.Background(), containerID)
 		assert.ErrorIs(t, err, biz.ErrExitedContainerUnexpired)
 		assert.Empty(t, sample)
 	}, eventuallyTimeout, eventuallyTick)
@@ -345,6 +346,9 @@ func TestAllMetricsPresent(t *testing.T) {
 			SoftLimitBytes:        262144000,
 		},
 		RestartCount: 2,
+		// the mocked inspect response has no configuration to report, nor a HostConfig to compute the security posture
+		Inventory: inventory.Items{},
+		Security:  nil,
 	}
 
 	// Create a tempDir that will be the root of our mocked filsystem
@@ -365,7 +369,7 @@ func TestAllMetricsPresent(t *testing.T) {
 	t.Run("Given a mockedFilesystem and previous CPU state Then processed metrics are as expected", func(t *testing.T) {
 		metrics := biz.NewProcessor(storer, cgroupFetcher, inspector, 0)
 
-		sample, err := metrics.Process(InspectorContainerID)
+		sample, err := metrics.Process(context.Background(), InspectorContainerID)
 		require.NoError(t, err)
 		assert.Equal(t, expectedSample, sample)
 	})
@@ -442,7 +446,7 @@ func TestBlkIOMetrics(t *testing.T) {
 
 			metrics := biz.NewProcessor(storer, dockerAPIFetcher, inspector, 0)
 
-			sample, err := metrics.Process(InspectorContainerID)
+			sample, err := metrics.Process(context.Background(), InspectorContainerID)
 			require.NoError(t, err)
 			assert.Equal(t, tc.ExpectedBlkIO, sample.BlkIO)
 		})
@@ -41,8 +41,8 @@ func NewCgroupsV2FetcherMock(hostRoot string, time time.Time, systemUsage uint64
 }
 
 // Fetch calls the wrapped fetcher and overrides the Time
-func (cgf *CgroupsFetcherV2Mock) Fetch(c container.InspectResponse) (raw.Metrics, error) {
-	metrics, err := cgf.cgroupsFetcher.Fetch(c)
+func (cgf *CgroupsFetcherV2Mock) Fetch(ctx context.Context, c container.InspectResponse) (raw.Metrics, error) {
+	metrics, err := cgf.cgroupsFetcher.Fetch(ctx, c)
 	if err != nil {
 		return raw.Metrics{}, err
 	}
@@ -77,8 +77,8 @@ func NewCgroupsFetcherMock(hostRoot string, time time.Time, systemUsage uint64)
 }
 
 // Fetch calls the wrapped fetcher and overrides the Time
-func (cgf *CgroupsFetcherMock) Fetch(c container.InspectResponse) (raw.Metrics, error) {
-	metrics, err := cgf.cgroupsFetcher.Fetch(c)
+func (cgf *CgroupsFetcherMock) Fetch(ctx context.Context, c container.InspectResponse) (raw.Metrics, error) {
+	metrics, err := cgf.cgroupsFetcher.Fetch(ctx, c)
 	if err != nil {
 		return raw.Metrics{}, err
 	}